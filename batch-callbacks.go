@@ -0,0 +1,21 @@
+package grados
+
+import "C"
+
+import (
+	"unsafe"
+)
+
+// The librados completion callback of batches. This is kept apart from batch.go since files exporting functions to C can
+// only have declarations in their preamble.
+
+//export batchCompleteCallback
+func batchCompleteCallback(completion unsafe.Pointer, arg unsafe.Pointer) {
+	op := completeBatchOp(uintptr(arg))
+	if op == nil {
+		return
+	}
+	// At most concurrency operations are in flight and the channel has as many slots, so this never blocks the
+	// librados callback thread.
+	op.completed <- op
+}
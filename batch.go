@@ -0,0 +1,280 @@
+package grados
+
+/*
+#cgo LDFLAGS: -lrados
+#include <stdint.h>
+#include <rados/librados.h>
+
+extern void batchCompleteCallback(void *completion, void *arg);
+
+// create_completion creates a completion passing the registry id of the batch operation to the callback.
+static int create_completion(uintptr_t id, rados_completion_t *completion) {
+	return rados_aio_create_completion((void *)id, (rados_callback_t)batchCompleteCallback, NULL, completion);
+}
+*/
+import "C"
+
+import (
+	"fmt"
+	"sync"
+)
+
+// Batch queues write transactions against many objects of a pool and applies them asynchronously with a limited number
+// of operations in flight. Use CreateBatch from a pool to create a valid instance.
+type Batch struct {
+	pool        *Pool
+	concurrency int
+	stopOnError bool
+	entries     []*batchEntry
+}
+
+type batchEntry struct {
+	object      string
+	transaction *WriteTransaction
+}
+
+// BatchResult is the result of applying a transaction to an object of the batch.
+type BatchResult struct {
+	Object  string // The object name.
+	Err     error  // The error if the transaction failed.
+	Version uint64 // The object version after the transaction was applied.
+	Skipped bool   // True if the transaction was not applied because an earlier transaction failed.
+}
+
+// Success returns true if the transaction was applied successfully.
+func (result *BatchResult) Success() bool {
+	return !result.Skipped && result.Err == nil
+}
+
+// BatchReport contains the results of a batch in the order the transactions were queued.
+type BatchReport struct {
+	Results []*BatchResult
+}
+
+// Failed returns the results of the transactions that failed.
+func (report *BatchReport) Failed() []*BatchResult {
+	failed := make([]*BatchResult, 0)
+	for _, result := range report.Results {
+		if result.Err != nil {
+			failed = append(failed, result)
+		}
+	}
+	return failed
+}
+
+// Skipped returns the results of the transactions that were not applied.
+func (report *BatchReport) Skipped() []*BatchResult {
+	skipped := make([]*BatchResult, 0)
+	for _, result := range report.Results {
+		if result.Skipped {
+			skipped = append(skipped, result)
+		}
+	}
+	return skipped
+}
+
+// CreateBatch creates an empty batch for the pool. At most concurrency operations will be in flight at a time.
+func (pool *Pool) CreateBatch(concurrency int) *Batch {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	return &Batch{
+		pool:        pool,
+		concurrency: concurrency,
+		entries:     make([]*batchEntry, 0),
+	}
+}
+
+// StopOnFirstError makes the batch stop dispatching transactions once a transaction fails. Transactions already in
+// flight are still completed.
+func (b *Batch) StopOnFirstError() *Batch {
+	b.stopOnError = true
+	return b
+}
+
+// Queue adds a transaction to apply to the named object.
+func (b *Batch) Queue(objectName string, transaction *WriteTransaction) *Batch {
+	b.entries = append(b.entries, &batchEntry{
+		object:      objectName,
+		transaction: transaction,
+	})
+	return b
+}
+
+// Len returns the number of queued transactions.
+func (b *Batch) Len() int {
+	return len(b.entries)
+}
+
+// Execute applies all the queued transactions and blocks until they are done. The returned report has a result for
+// every queued transaction.
+//
+// Operations are started asynchronously by the calling goroutine, which waits for a free slot once concurrency
+// operations are in flight. Completions are reported by librados and collected by a single goroutine, so no goroutine
+// is blocked per operation.
+func (b *Batch) Execute(flags ...LibradosOperation) *BatchReport {
+	var f C.int = 0
+	for _, flag := range flags {
+		f |= C.int(flag)
+	}
+
+	report := &BatchReport{
+		Results: make([]*BatchResult, len(b.entries)),
+	}
+
+	slots := make(chan struct{}, b.concurrency)
+	completed := make(chan *batchOp, b.concurrency)
+	stop := make(chan struct{})
+	var once sync.Once
+	fail := func(result *BatchResult) {
+		if result.Err != nil && b.stopOnError {
+			once.Do(func() { close(stop) })
+		}
+	}
+
+	var inFlight sync.WaitGroup
+	collected := make(chan struct{})
+	go func() {
+		defer close(collected)
+		for op := range completed {
+			result := op.finish()
+			report.Results[op.index] = result
+			fail(result)
+			<-slots
+			inFlight.Done()
+		}
+	}()
+
+dispatch:
+	for i, entry := range b.entries {
+		select {
+		case <-stop:
+			break dispatch
+		case slots <- struct{}{}:
+		}
+		select {
+		case <-stop:
+			<-slots
+			break dispatch
+		default:
+		}
+		inFlight.Add(1)
+		if result := b.start(i, entry, f, completed); result != nil {
+			report.Results[i] = result
+			fail(result)
+			<-slots
+			inFlight.Done()
+		}
+	}
+	inFlight.Wait()
+	close(completed)
+	<-collected
+
+	for i, result := range report.Results {
+		if result == nil {
+			report.Results[i] = &BatchResult{
+				Object:  b.entries[i].object,
+				Skipped: true,
+			}
+		}
+	}
+	return report
+}
+
+// batchOp is an asynchronous write operation of a batch in flight.
+type batchOp struct {
+	index      int
+	object     string
+	oid        *C.char
+	wo         *WriteOperation
+	completion C.rados_completion_t
+	completed  chan<- *batchOp
+}
+
+// batchOps keeps track of the operations in flight so the librados completion callback can find them. librados only
+// passes the id of the operation back to the callback since Go pointers can't be held by C code.
+var batchOps = struct {
+	sync.Mutex
+	next uintptr
+	m    map[uintptr]*batchOp
+}{m: make(map[uintptr]*batchOp)}
+
+// start starts the transaction of the entry asynchronously. The operation is passed to the completed channel once it
+// completes. If the operation could not be started, its result is returned.
+func (b *Batch) start(index int, entry *batchEntry, flags C.int, completed chan<- *batchOp) *BatchResult {
+	if err := entry.transaction.Validate(); err != nil {
+		return &BatchResult{Object: entry.object, Err: err}
+	}
+	wo, err := newWriteOperation(b.pool.context, b.pool.alignment)
+	if err != nil {
+		return &BatchResult{Object: entry.object, Err: err}
+	}
+	entry.transaction.compile(wo)
+	if wo.err != nil {
+		wo.Release()
+		return &BatchResult{Object: entry.object, Err: wo.err}
+	}
+
+	op := &batchOp{
+		index:     index,
+		object:    entry.object,
+		oid:       C.CString(entry.object),
+		wo:        wo,
+		completed: completed,
+	}
+	batchOps.Lock()
+	batchOps.next++
+	id := batchOps.next
+	batchOps.m[id] = op
+	batchOps.Unlock()
+
+	ret := C.create_completion(C.uintptr_t(id), &op.completion)
+	if err := toRadosError(ret); err != nil {
+		completeBatchOp(id)
+		op.release()
+		err.Message = "Unable to create completion."
+		return &BatchResult{Object: entry.object, Err: err}
+	}
+
+	ret = C.rados_aio_write_op_operate(wo.opContext, wo.ioContext, op.completion, op.oid, nil, flags)
+	if err := toRadosError(ret); err != nil {
+		completeBatchOp(id)
+		op.release()
+		err.Message = fmt.Sprintf("Unable to start write operation on object %s.", entry.object)
+		return &BatchResult{Object: entry.object, Err: err}
+	}
+	return nil
+}
+
+// finish returns the result of the completed operation and releases it.
+func (op *batchOp) finish() *BatchResult {
+	defer op.release()
+	result := &BatchResult{
+		Object: op.object,
+	}
+	ret := C.rados_aio_get_return_value(op.completion)
+	if err := toRadosError(ret); err != nil {
+		err.Message = fmt.Sprintf("Unable to perform write operation on object %s.", op.object)
+		result.Err = err
+		return result
+	}
+	result.Version = uint64(C.rados_aio_get_version(op.completion))
+	return result
+}
+
+func (op *batchOp) release() {
+	if op.completion != nil {
+		C.rados_aio_release(op.completion)
+	}
+	op.wo.Release()
+	freeString(op.oid)
+}
+
+// completeBatchOp removes the operation from the registry and returns it.
+func completeBatchOp(id uintptr) *batchOp {
+	batchOps.Lock()
+	defer batchOps.Unlock()
+	op := batchOps.m[id]
+	delete(batchOps.m, id)
+	return op
+}
@@ -0,0 +1,63 @@
+package grados
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+)
+
+func TestBatchExecute(t *testing.T) {
+	cluster := connect(t)
+	if cluster == nil {
+		return
+	}
+	defer cluster.Shutdown()
+
+	pool, err := cluster.ManagePool("data")
+	handleError(t, err)
+	if pool == nil {
+		return
+	}
+	defer pool.Close()
+
+	batch := pool.CreateBatch(4)
+	tx := NewWriteTransaction().SetAttribute("batch", bytes.NewBufferString("done"))
+	for i := 0; i < 10; i++ {
+		name := fmt.Sprintf("batch_object%d", i)
+		pool.ManageObject(name).WriteFull(bytes.NewBufferString("data"))
+		batch.Queue(name, tx)
+	}
+	report := batch.Execute()
+	if len(report.Results) != 10 {
+		t.Errorf("report should have 10 results, has %d", len(report.Results))
+	}
+	for _, result := range report.Failed() {
+		t.Errorf("%s failed: %s", result.Object, result.Err)
+	}
+	for i := 0; i < 10; i++ {
+		pool.ManageObject(fmt.Sprintf("batch_object%d", i)).Remove()
+	}
+}
+
+func TestBatchStopOnFirstError(t *testing.T) {
+	cluster := connect(t)
+	if cluster == nil {
+		return
+	}
+	defer cluster.Shutdown()
+
+	pool, err := cluster.ManagePool("data")
+	handleError(t, err)
+	if pool == nil {
+		return
+	}
+	defer pool.Close()
+
+	batch := pool.CreateBatch(1).StopOnFirstError()
+	batch.Queue("batch_missing", NewWriteTransaction().AssertExists().Remove())
+	batch.Queue("batch_missing", NewWriteTransaction().AssertExists().Remove())
+	report := batch.Execute()
+	if len(report.Failed()) != 1 || len(report.Skipped()) != 1 {
+		t.Errorf("should have 1 failed and 1 skipped, has %d and %d", len(report.Failed()), len(report.Skipped()))
+	}
+}
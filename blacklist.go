@@ -0,0 +1,106 @@
+package grados
+
+/*
+#cgo LDFLAGS: -lrados
+#include <errno.h>
+#include <rados/librados.h>
+
+// rados_getaddrs is only available starting with nautilus.
+static int get_addrs(rados_t cluster, char **addrs) {
+#ifdef LIBRADOS_SUPPORTS_GETADDRS
+	return rados_getaddrs(cluster, addrs);
+#else
+	return -ENOSYS;
+#endif
+}
+*/
+import "C"
+
+import (
+	"fmt"
+	"syscall"
+	"time"
+)
+
+// BlacklistEntry is a blacklisted client address.
+type BlacklistEntry struct {
+	Address string    // The blacklisted address.
+	Until   time.Time // When the entry expires.
+}
+
+// Blacklist blacklists the client address so the OSDs reject its operations until the entry expires. This is used to
+// fence a client before breaking its locks. An expiration of 0 uses the cluster default.
+func (cluster *Cluster) Blacklist(address string, expire time.Duration) error {
+	addr := C.CString(address)
+	defer freeString(addr)
+	ret := C.rados_blacklist_add(cluster.handle, addr, C.uint32_t(expire/time.Second))
+	if err := toRadosError(ret); err != nil {
+		err.Message = fmt.Sprintf("Unable to blacklist %s.", address)
+		return err
+	}
+	return nil
+}
+
+// ListBlacklist returns the blacklisted client addresses.
+func (cluster *Cluster) ListBlacklist() ([]*BlacklistEntry, error) {
+	var output []struct {
+		Address string `json:"addr"`
+		Until   string `json:"until"`
+	}
+	if err := cluster.blacklistCommand("", "", &output); err != nil {
+		return nil, err
+	}
+	entries := make([]*BlacklistEntry, len(output))
+	for i, entry := range output {
+		entries[i] = &BlacklistEntry{
+			Address: entry.Address,
+			Until:   parseMonTime(entry.Until),
+		}
+	}
+	return entries, nil
+}
+
+// RemoveBlacklist removes the client address from the blacklist.
+func (cluster *Cluster) RemoveBlacklist(address string) error {
+	return cluster.blacklistCommand("rm", address, nil)
+}
+
+// Address returns the address of the current client as it would appear in lockers and in the blacklist. This requires
+// librados from nautilus or later.
+func (cluster *Cluster) Address() (string, error) {
+	var addrs *C.char
+	ret := C.get_addrs(cluster.handle, &addrs)
+	if err := toRadosError(ret); err != nil {
+		err.Message = "Unable to get the client address."
+		return "", err
+	}
+	defer C.rados_buffer_free(addrs)
+	return C.GoString(addrs), nil
+}
+
+// blacklistCommand runs an osd blacklist mon command. An empty op lists the blacklist. Releases starting with pacific
+// renamed the command to osd blocklist, which is tried if the blacklist command is not recognized.
+func (cluster *Cluster) blacklistCommand(op, address string, result interface{}) error {
+	var err error
+	for _, name := range []string{"blacklist", "blocklist"} {
+		args := map[string]interface{}{"prefix": "osd " + name + " ls"}
+		if op != "" {
+			args = map[string]interface{}{"prefix": "osd " + name, name + "op": op, "addr": address}
+		}
+		err = cluster.monCommand(args, result)
+		if radosErr, ok := err.(*RadosError); !ok || radosErr.Code != -int(syscall.EINVAL) {
+			return err
+		}
+	}
+	return err
+}
+
+// parseMonTime parses the timestamps of mon command outputs. The zero time is returned if the format is unknown.
+func parseMonTime(value string) time.Time {
+	for _, layout := range []string{"2006-01-02 15:04:05.000000", "2006-01-02T15:04:05.000000-0700", time.RFC3339Nano} {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t
+		}
+	}
+	return time.Time{}
+}
@@ -0,0 +1,44 @@
+package grados
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBlacklist(t *testing.T) {
+	cluster := connect(t)
+	if cluster == nil {
+		return
+	}
+	defer cluster.Shutdown()
+
+	address := "127.0.0.1:0/123456"
+	handleError(t, cluster.Blacklist(address, time.Minute))
+
+	entries, err := cluster.ListBlacklist()
+	handleError(t, err)
+	found := false
+	for _, entry := range entries {
+		if normalizeAddress(entry.Address) == address {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("%s should be blacklisted", address)
+	}
+
+	handleError(t, cluster.RemoveBlacklist(address))
+
+	self, err := cluster.Address()
+	handleError(t, err)
+	t.Log("Address:", self)
+}
+
+func TestParseMonTime(t *testing.T) {
+	if parseMonTime("2015-03-01 10:30:00.123456").IsZero() {
+		t.Error("should parse mon timestamp")
+	}
+	if !parseMonTime("garbage").IsZero() {
+		t.Error("should return zero time for unknown format")
+	}
+}
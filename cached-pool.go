@@ -0,0 +1,376 @@
+package grados
+
+import (
+	"bytes"
+	"container/list"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+)
+
+// cacheNotifyTimeout is how long writes through a CachedPool wait for the other caches to acknowledge invalidations.
+const cacheNotifyTimeout = 5 * time.Second
+
+// CachedPool is a read-through cache in front of a pool. Object data, attributes and status are kept in an LRU cache
+// bounded by number of entries and bytes. A watch is registered on every cached object and its entries are invalidated
+// when a notify is received, so writers outside the cache should notify the object after writing. Writes done through
+// the cache invalidate the entries and notify the object automatically. The watch of an object is kept across
+// invalidations and released when the object has no entries left after eviction, or when more than maxEntries objects
+// are watched. Use Cached from a pool to create a valid instance.
+type CachedPool struct {
+	pool       *Pool
+	maxEntries int
+	maxBytes   int
+
+	mutex   sync.Mutex
+	lru     *list.List
+	entries map[string]*list.Element
+	objects map[string]*cachedObjectState
+	idle    *list.List
+	bytes   int
+}
+
+// cacheEntry is a cached value. Read data and attributes are kept in data, status in status.
+type cacheEntry struct {
+	key    string
+	object string
+	data   []byte
+	status *ObjectStatus
+}
+
+// cachedObjectState tracks the entries and the watch of a cached object. The generation is incremented on every
+// invalidation so entries loaded before an invalidation are not cached. Objects without entries are kept in the idle
+// list.
+type cachedObjectState struct {
+	name       string
+	keys       map[string]bool
+	generation uint64
+	watch      *Watch
+	stop       chan struct{}
+	idle       *list.Element
+}
+
+// Cached wraps the pool in a cache keeping at most maxEntries entries and maxBytes bytes of data.
+func (pool *Pool) Cached(maxEntries, maxBytes int) *CachedPool {
+	return &CachedPool{
+		pool:       pool,
+		maxEntries: maxEntries,
+		maxBytes:   maxBytes,
+		lru:        list.New(),
+		entries:    make(map[string]*list.Element),
+		objects:    make(map[string]*cachedObjectState),
+		idle:       list.New(),
+	}
+}
+
+// Pool returns the underlying pool.
+func (cp *CachedPool) Pool() *Pool {
+	return cp.pool
+}
+
+// Len returns the number of cached entries.
+func (cp *CachedPool) Len() int {
+	cp.mutex.Lock()
+	defer cp.mutex.Unlock()
+	return cp.lru.Len()
+}
+
+// Invalidate drops all the cached entries of the object. The object is still watched.
+func (cp *CachedPool) Invalidate(objectName string) {
+	cp.mutex.Lock()
+	defer cp.mutex.Unlock()
+	cp.invalidate(objectName)
+}
+
+// Close drops all the cached entries and unregisters the watches. The underlying pool is not closed.
+func (cp *CachedPool) Close() {
+	cp.mutex.Lock()
+	defer cp.mutex.Unlock()
+	for _, state := range cp.objects {
+		cp.drop(state)
+	}
+}
+
+// CachedObject is an object accessed through a CachedPool. Use ManageObject from a cached pool to create a valid
+// instance.
+type CachedObject struct {
+	cache  *CachedPool
+	object *Object
+}
+
+// ManageObject manages an object through the cache.
+func (cp *CachedPool) ManageObject(name string) *CachedObject {
+	return &CachedObject{
+		cache:  cp,
+		object: cp.pool.ManageObject(name),
+	}
+}
+
+// Object returns the uncached object.
+func (co *CachedObject) Object() *Object {
+	return co.object
+}
+
+// Read reads a specified length of data from the object starting at the given offset. The data is served from the
+// cache if the same range was read before.
+func (co *CachedObject) Read(length, offset uint64) (io.Reader, error) {
+	key := fmt.Sprintf("%s\x00read\x00%d\x00%d", co.object.name, length, offset)
+	entry, err := co.cache.get(co.object, key, func() (*cacheEntry, error) {
+		data, err := co.object.Read(length, offset)
+		if err != nil {
+			return nil, err
+		}
+		return &cacheEntry{data: readAll(data)}, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return bytes.NewReader(entry.data), nil
+}
+
+// Attribute returns an extended attribute of the object, from the cache if possible.
+func (co *CachedObject) Attribute(attributeName string) (io.Reader, error) {
+	key := fmt.Sprintf("%s\x00attr\x00%s", co.object.name, attributeName)
+	entry, err := co.cache.get(co.object, key, func() (*cacheEntry, error) {
+		value, err := co.object.Attribute(attributeName)
+		if err != nil {
+			return nil, err
+		}
+		return &cacheEntry{data: readAll(value)}, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return bytes.NewReader(entry.data), nil
+}
+
+// Status returns the status of the object, from the cache if possible.
+func (co *CachedObject) Status() (*ObjectStatus, error) {
+	key := fmt.Sprintf("%s\x00status", co.object.name)
+	entry, err := co.cache.get(co.object, key, func() (*cacheEntry, error) {
+		status, err := co.object.Status()
+		if err != nil {
+			return nil, err
+		}
+		return &cacheEntry{status: status}, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	status := *entry.status
+	return &status, nil
+}
+
+// Write writes the data at a specific offset to the object and invalidates the caches of the object.
+func (co *CachedObject) Write(data io.Reader, offset uint64) error {
+	return co.write(co.object.Write(data, offset))
+}
+
+// WriteFull writes the entire data to the object and invalidates the caches of the object.
+func (co *CachedObject) WriteFull(data io.Reader) error {
+	return co.write(co.object.WriteFull(data))
+}
+
+// Append appends data to the object and invalidates the caches of the object.
+func (co *CachedObject) Append(data io.Reader) error {
+	return co.write(co.object.Append(data))
+}
+
+// Truncate resizes the object and invalidates the caches of the object.
+func (co *CachedObject) Truncate(size uint64) error {
+	return co.write(co.object.Truncate(size))
+}
+
+// SetAttribute sets an extended attribute of the object and invalidates the caches of the object.
+func (co *CachedObject) SetAttribute(attributeName string, attributeValue io.Reader) error {
+	return co.write(co.object.SetAttribute(attributeName, attributeValue))
+}
+
+// RemoveAttribute removes an extended attribute of the object and invalidates the caches of the object.
+func (co *CachedObject) RemoveAttribute(attributeName string) error {
+	return co.write(co.object.RemoveAttribute(attributeName))
+}
+
+// Remove removes the object and invalidates the caches of the object.
+func (co *CachedObject) Remove() error {
+	return co.write(co.object.Remove())
+}
+
+// write invalidates the local entries of the object and notifies the other caches watching it. Notify errors are
+// ignored since the watchers of a removed object are invalidated through their watch errors.
+func (co *CachedObject) write(err error) error {
+	co.cache.Invalidate(co.object.name)
+	if err != nil {
+		return err
+	}
+	co.object.Notify(bytes.NewBufferString(""), cacheNotifyTimeout)
+	return nil
+}
+
+// get returns the cached entry for the key or loads it. Entries are only cached if the object was not invalidated while
+// loading.
+func (cp *CachedPool) get(object *Object, key string, load func() (*cacheEntry, error)) (*cacheEntry, error) {
+	cp.mutex.Lock()
+	if element, ok := cp.entries[key]; ok {
+		cp.lru.MoveToFront(element)
+		cp.mutex.Unlock()
+		return element.Value.(*cacheEntry), nil
+	}
+	state := cp.objects[object.name]
+	cp.mutex.Unlock()
+
+	if state == nil {
+		if state = cp.watch(object); state == nil {
+			return load()
+		}
+	}
+	cp.mutex.Lock()
+	generation := state.generation
+	cp.mutex.Unlock()
+
+	entry, err := load()
+	if err != nil {
+		return nil, err
+	}
+	entry.key = key
+	entry.object = object.name
+
+	cp.mutex.Lock()
+	defer cp.mutex.Unlock()
+	if cp.objects[object.name] != state || state.generation != generation {
+		return entry, nil
+	}
+	if element, ok := cp.entries[key]; ok {
+		cp.lru.MoveToFront(element)
+		return element.Value.(*cacheEntry), nil
+	}
+	if len(entry.data) > cp.maxBytes {
+		return entry, nil
+	}
+	cp.entries[key] = cp.lru.PushFront(entry)
+	state.keys[key] = true
+	if state.idle != nil {
+		cp.idle.Remove(state.idle)
+		state.idle = nil
+	}
+	cp.bytes += len(entry.data)
+	cp.evict()
+	return entry, nil
+}
+
+// watch registers a watch on the object so its entries are invalidated on notifies. This returns nil if the object
+// can't be watched, in which case nothing is cached for the object.
+func (cp *CachedPool) watch(object *Object) *cachedObjectState {
+	watch, err := object.Watch()
+	if err != nil {
+		return nil
+	}
+	state := &cachedObjectState{
+		name:  object.name,
+		keys:  make(map[string]bool),
+		watch: watch,
+		stop:  make(chan struct{}),
+	}
+
+	cp.mutex.Lock()
+	if existing, ok := cp.objects[object.name]; ok {
+		cp.mutex.Unlock()
+		watch.Close()
+		return existing
+	}
+	cp.objects[object.name] = state
+	state.idle = cp.idle.PushFront(state)
+	cp.trimIdle()
+	cp.mutex.Unlock()
+
+	go func() {
+		for {
+			select {
+			case <-state.stop:
+				return
+			case <-watch.Notifications():
+				cp.Invalidate(object.name)
+			case <-watch.Dropped():
+				cp.Invalidate(object.name)
+			case <-watch.Errors():
+				// the watch is no longer valid, so the object is watched again on the next read
+				cp.mutex.Lock()
+				if cp.objects[object.name] == state {
+					cp.drop(state)
+				}
+				cp.mutex.Unlock()
+				return
+			}
+		}
+	}()
+	return state
+}
+
+// invalidate drops the entries of the object but keeps watching it. The mutex must be held.
+func (cp *CachedPool) invalidate(objectName string) {
+	state, ok := cp.objects[objectName]
+	if !ok {
+		return
+	}
+	cp.removeEntries(state)
+	state.generation++
+	if state.idle == nil {
+		state.idle = cp.idle.PushFront(state)
+	}
+	cp.trimIdle()
+}
+
+// evict removes the least recently used entries until the cache is within bounds. Objects left without entries are
+// no longer watched. The mutex must be held.
+func (cp *CachedPool) evict() {
+	for cp.lru.Len() > 0 && (cp.lru.Len() > cp.maxEntries || cp.bytes > cp.maxBytes) {
+		element := cp.lru.Back()
+		entry := element.Value.(*cacheEntry)
+		cp.remove(element)
+		state := cp.objects[entry.object]
+		delete(state.keys, entry.key)
+		if len(state.keys) == 0 {
+			cp.drop(state)
+		}
+	}
+}
+
+// trimIdle stops watching the objects without entries, least recently invalidated first, until at most maxEntries
+// objects are watched. The mutex must be held.
+func (cp *CachedPool) trimIdle() {
+	for len(cp.objects) > cp.maxEntries && cp.idle.Len() > 0 {
+		cp.drop(cp.idle.Back().Value.(*cachedObjectState))
+	}
+}
+
+// remove removes an entry from the cache. The mutex must be held.
+func (cp *CachedPool) remove(element *list.Element) {
+	entry := element.Value.(*cacheEntry)
+	cp.lru.Remove(element)
+	delete(cp.entries, entry.key)
+	cp.bytes -= len(entry.data)
+}
+
+// removeEntries removes all the entries of the object. The mutex must be held.
+func (cp *CachedPool) removeEntries(state *cachedObjectState) {
+	for key := range state.keys {
+		if element, ok := cp.entries[key]; ok {
+			cp.remove(element)
+		}
+	}
+	state.keys = make(map[string]bool)
+}
+
+// drop removes the entries of the object, forgets it and unregisters its watch. Unwatching is done in the background
+// since it waits for pending callbacks. The mutex must be held.
+func (cp *CachedPool) drop(state *cachedObjectState) {
+	cp.removeEntries(state)
+	delete(cp.objects, state.name)
+	if state.idle != nil {
+		cp.idle.Remove(state.idle)
+		state.idle = nil
+	}
+	close(state.stop)
+	go state.watch.Close()
+}
@@ -0,0 +1,60 @@
+package grados
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+func TestCachedPool(t *testing.T) {
+	cluster := connect(t)
+	if cluster == nil {
+		return
+	}
+	defer cluster.Shutdown()
+
+	pool, err := cluster.ManagePool("data")
+	handleError(t, err)
+	if pool == nil {
+		return
+	}
+	defer pool.Close()
+
+	cache := pool.Cached(2, 1024)
+	defer cache.Close()
+
+	object := cache.ManageObject("cached_object")
+	handleError(t, object.WriteFull(bytes.NewBufferString("data1")))
+	defer object.Remove()
+
+	read := func() string {
+		data, err := object.Read(5, 0)
+		handleError(t, err)
+		if data == nil {
+			return ""
+		}
+		return string(readAll(data))
+	}
+
+	if value := read(); value != "data1" {
+		t.Errorf("value should be data1, value is %s", value)
+	}
+	if cache.Len() != 1 {
+		t.Errorf("cache should have 1 entry, has %d", cache.Len())
+	}
+
+	// a write outside the cache followed by a notify invalidates the cache
+	handleError(t, object.Object().WriteFull(bytes.NewBufferString("data2")))
+	object.Object().Notify(bytes.NewBufferString(""), time.Second)
+	time.Sleep(100 * time.Millisecond)
+	if value := read(); value != "data2" {
+		t.Errorf("value should be data2, value is %s", value)
+	}
+
+	// entries are evicted beyond the entry limit
+	object.Status()
+	object.Read(1, 0)
+	if cache.Len() != 2 {
+		t.Errorf("cache should have 2 entries, has %d", cache.Len())
+	}
+}
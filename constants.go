@@ -22,6 +22,9 @@ type LibradosOperation int
 // LibradosLock are object lock flags.
 type LibradosLock int
 
+// ChecksumType are the hash algorithms supported by the checksum operation.
+type ChecksumType int
+
 const (
 	OperationExclusive LibradosOpFlag = C.LIBRADOS_OP_FLAG_EXCL   // Fails a create operation if the object already exists.
 	OperationFailOk    LibradosOpFlag = C.LIBRADOS_OP_FLAG_FAILOK // Allows the transaction to succeed even if the flagged operation fails.
@@ -47,4 +50,8 @@ const (
 	NoSnapshot = C.LIBRADOS_SNAP_HEAD // Use this to disable snapshop selection when performing object operations.
 
 	Renew LibradosLock = C.LIBRADOS_LOCK_FLAG_RENEW // Lock Flag. Not much detail in Librados API.
+
+	ChecksumXXHash32 ChecksumType = C.LIBRADOS_CHECKSUM_TYPE_XXHASH32 // 32 bit xxHash. Seeded with 0.
+	ChecksumXXHash64 ChecksumType = C.LIBRADOS_CHECKSUM_TYPE_XXHASH64 // 64 bit xxHash. Seeded with 0.
+	ChecksumCRC32C   ChecksumType = C.LIBRADOS_CHECKSUM_TYPE_CRC32C   // CRC32C. Seeded with 0xffffffff.
 )
@@ -62,11 +62,13 @@ Other features implemented are:
  - managed-snapshots
  - read/write transactions
  - object extended attributes
+ - object watch/notify
+ - object locks, leases and leader election
+ - erasure coded pools and profiles
 
 Missing implementation:
  - OMAP/TMAP operations (TODO)
  - class executions (TODO)
  - mon/osd/pg commands (necessary?)
- - watch/unwatch/notify objects (still looking for a way to do this)
 */
 package grados
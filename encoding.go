@@ -0,0 +1,112 @@
+package grados
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"syscall"
+)
+
+// cephEncoder encodes values using the ceph wire encoding expected by object classes. All integers are little endian
+// and strings are prefixed by their 32 bit length.
+type cephEncoder struct {
+	buf bytes.Buffer
+}
+
+func (e *cephEncoder) u8(v uint8) {
+	e.buf.WriteByte(v)
+}
+
+func (e *cephEncoder) u32(v uint32) {
+	binary.Write(&e.buf, binary.LittleEndian, v)
+}
+
+func (e *cephEncoder) u64(v uint64) {
+	binary.Write(&e.buf, binary.LittleEndian, v)
+}
+
+func (e *cephEncoder) string(v string) {
+	e.u32(uint32(len(v)))
+	e.buf.WriteString(v)
+}
+
+// versioned encodes a versioned struct. The struct is prefixed by its version, compatible version and length.
+func (e *cephEncoder) versioned(version, compat uint8, content func(e *cephEncoder)) {
+	inner := new(cephEncoder)
+	content(inner)
+	e.u8(version)
+	e.u8(compat)
+	e.u32(uint32(inner.buf.Len()))
+	e.buf.Write(inner.buf.Bytes())
+}
+
+func (e *cephEncoder) Bytes() []byte {
+	return e.buf.Bytes()
+}
+
+// cephDecoder decodes values using the ceph wire encoding. Decoding stops at the first error which is kept in err.
+type cephDecoder struct {
+	buf []byte
+	pos int
+	err error
+}
+
+func newCephDecoder(buf []byte) *cephDecoder {
+	return &cephDecoder{buf: buf}
+}
+
+func (d *cephDecoder) next(n int) []byte {
+	if d.err != nil {
+		return nil
+	}
+	if n < 0 || d.pos+n > len(d.buf) {
+		d.err = &RadosError{
+			Code:    -int(syscall.EINVAL),
+			Message: fmt.Sprintf("Unable to decode %d bytes at offset %d of %d bytes.", n, d.pos, len(d.buf)),
+		}
+		return nil
+	}
+	b := d.buf[d.pos : d.pos+n]
+	d.pos += n
+	return b
+}
+
+func (d *cephDecoder) u8() uint8 {
+	if b := d.next(1); b != nil {
+		return b[0]
+	}
+	return 0
+}
+
+func (d *cephDecoder) u32() uint32 {
+	if b := d.next(4); b != nil {
+		return binary.LittleEndian.Uint32(b)
+	}
+	return 0
+}
+
+func (d *cephDecoder) u64() uint64 {
+	if b := d.next(8); b != nil {
+		return binary.LittleEndian.Uint64(b)
+	}
+	return 0
+}
+
+func (d *cephDecoder) string() string {
+	return string(d.next(int(d.u32())))
+}
+
+// versioned decodes a versioned struct. Fields added by newer versions that are not read by content are skipped.
+func (d *cephDecoder) versioned(content func(d *cephDecoder)) {
+	d.u8() // version
+	d.u8() // compatible version
+	length := int(d.u32())
+	if d.err != nil {
+		return
+	}
+	end := d.pos + length
+	content(d)
+	if d.err == nil && d.pos <= end {
+		d.next(end - d.pos)
+	}
+}
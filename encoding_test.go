@@ -0,0 +1,47 @@
+package grados
+
+import "testing"
+
+func TestCephEncoding(t *testing.T) {
+	e := new(cephEncoder)
+	e.versioned(2, 1, func(e *cephEncoder) {
+		e.u32(2)
+		e.string("lock1")
+		e.string("lock2")
+		e.u64(42) // field unknown to the decoder
+	})
+	e.u8(7)
+
+	d := newCephDecoder(e.Bytes())
+	locks := make([]string, 0)
+	d.versioned(func(d *cephDecoder) {
+		count := d.u32()
+		for i := uint32(0); i < count; i++ {
+			locks = append(locks, d.string())
+		}
+	})
+	last := d.u8()
+	handleError(t, d.err)
+	if len(locks) != 2 || locks[0] != "lock1" || locks[1] != "lock2" {
+		t.Errorf("locks should be [lock1 lock2], locks are %v", locks)
+	}
+	if last != 7 {
+		t.Errorf("unknown fields should be skipped, last is %d", last)
+	}
+}
+
+func TestCephDecodingShortBuffer(t *testing.T) {
+	d := newCephDecoder([]byte{1, 1, 10, 0, 0, 0, 1})
+	d.versioned(func(d *cephDecoder) {
+		d.u64()
+	})
+	if d.err == nil {
+		t.Error("should fail on short buffer")
+	}
+}
+
+func TestEntityName(t *testing.T) {
+	if name := entityName(0x08, 4123); name != "client.4123" {
+		t.Errorf("name should be client.4123, name is %s", name)
+	}
+}
@@ -0,0 +1,124 @@
+package grados
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+)
+
+// ErasureCodeProfile is an erasure code profile, mapping its settings to their values. Common settings are k, m,
+// plugin, technique and crush-failure-domain.
+type ErasureCodeProfile map[string]string
+
+// ErasurePoolOptions are the optional settings of CreateErasurePool.
+type ErasurePoolOptions struct {
+	PgNum           uint   // The number of placement groups. The cluster default is used if 0.
+	PgpNum          uint   // The number of placement groups used for placement. PgNum is used if 0.
+	CrushRule       string // The name of the crush rule. A rule is created from the profile if empty.
+	AllowOverwrites bool   // Allow partial overwrites, required by rbd and cephfs. This also lifts the alignment of appends.
+}
+
+// CreateErasurePool creates an erasure coded pool using the named erasure code profile. The options can be nil.
+func (cluster *Cluster) CreateErasurePool(poolName, profile string, options *ErasurePoolOptions) error {
+	if options == nil {
+		options = new(ErasurePoolOptions)
+	}
+	args := map[string]interface{}{
+		"prefix":               "osd pool create",
+		"pool":                 poolName,
+		"pool_type":            "erasure",
+		"erasure_code_profile": profile,
+	}
+	if options.PgNum > 0 {
+		args["pg_num"] = options.PgNum
+		args["pgp_num"] = options.PgNum
+	}
+	if options.PgpNum > 0 {
+		args["pgp_num"] = options.PgpNum
+	}
+	if options.CrushRule != "" {
+		args["rule"] = options.CrushRule
+	}
+	if err := cluster.monCommand(args, nil); err != nil {
+		return err
+	}
+	if options.AllowOverwrites {
+		args := map[string]interface{}{
+			"prefix": "osd pool set",
+			"pool":   poolName,
+			"var":    "allow_ec_overwrites",
+			"val":    "true",
+		}
+		return cluster.monCommand(args, nil)
+	}
+	return nil
+}
+
+// SetErasureCodeProfile creates the named erasure code profile. An existing profile with different settings is only
+// replaced if force is true, which does not affect the pools already using it.
+func (cluster *Cluster) SetErasureCodeProfile(name string, profile ErasureCodeProfile, force bool) error {
+	keys := make([]string, 0, len(profile))
+	for key := range profile {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	settings := make([]string, len(keys))
+	for i, key := range keys {
+		settings[i] = fmt.Sprintf("%s=%s", key, profile[key])
+	}
+	args := map[string]interface{}{
+		"prefix":  "osd erasure-code-profile set",
+		"name":    name,
+		"profile": settings,
+	}
+	if force {
+		args["force"] = true
+	}
+	return cluster.monCommand(args, nil)
+}
+
+// GetErasureCodeProfile returns the settings of the named erasure code profile.
+func (cluster *Cluster) GetErasureCodeProfile(name string) (ErasureCodeProfile, error) {
+	profile := make(ErasureCodeProfile)
+	args := map[string]interface{}{
+		"prefix": "osd erasure-code-profile get",
+		"name":   name,
+	}
+	if err := cluster.monCommand(args, &profile); err != nil {
+		return nil, err
+	}
+	return profile, nil
+}
+
+// ListErasureCodeProfiles returns the names of the erasure code profiles.
+func (cluster *Cluster) ListErasureCodeProfiles() ([]string, error) {
+	profiles := make([]string, 0)
+	args := map[string]interface{}{
+		"prefix": "osd erasure-code-profile ls",
+	}
+	if err := cluster.monCommand(args, &profiles); err != nil {
+		return nil, err
+	}
+	return profiles, nil
+}
+
+// RemoveErasureCodeProfile removes the named erasure code profile. Profiles used by pools can't be removed.
+func (cluster *Cluster) RemoveErasureCodeProfile(name string) error {
+	args := map[string]interface{}{
+		"prefix": "osd erasure-code-profile rm",
+		"name":   name,
+	}
+	return cluster.monCommand(args, nil)
+}
+
+// DataChunks returns k, the number of data chunks of the profile, or 0 if it is not set.
+func (profile ErasureCodeProfile) DataChunks() int {
+	k, _ := strconv.Atoi(profile["k"])
+	return k
+}
+
+// CodingChunks returns m, the number of coding chunks of the profile, or 0 if it is not set.
+func (profile ErasureCodeProfile) CodingChunks() int {
+	m, _ := strconv.Atoi(profile["m"])
+	return m
+}
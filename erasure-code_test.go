@@ -0,0 +1,95 @@
+package grados
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+func TestErasureCodeProfiles(t *testing.T) {
+	cluster := connect(t)
+	if cluster == nil {
+		return
+	}
+	defer cluster.Shutdown()
+
+	profile := ErasureCodeProfile{"k": "2", "m": "1", "crush-failure-domain": "osd"}
+	handleError(t, cluster.SetErasureCodeProfile("grados_test", profile, false))
+	defer cluster.RemoveErasureCodeProfile("grados_test")
+
+	stored, err := cluster.GetErasureCodeProfile("grados_test")
+	handleError(t, err)
+	if stored.DataChunks() != 2 || stored.CodingChunks() != 1 {
+		t.Errorf("profile should have k=2 and m=1, has %v", stored)
+	}
+
+	profiles, err := cluster.ListErasureCodeProfiles()
+	handleError(t, err)
+	found := false
+	for _, name := range profiles {
+		found = found || name == "grados_test"
+	}
+	if !found {
+		t.Errorf("profiles should include grados_test, are %v", profiles)
+	}
+}
+
+func TestCreateErasurePool(t *testing.T) {
+	cluster := connect(t)
+	if cluster == nil {
+		return
+	}
+	defer cluster.Shutdown()
+
+	handleError(t, cluster.SetErasureCodeProfile("grados_pool_test", ErasureCodeProfile{"k": "2", "m": "1", "crush-failure-domain": "osd"}, false))
+	defer cluster.RemoveErasureCodeProfile("grados_pool_test")
+
+	handleError(t, cluster.CreateErasurePool("erasureTest", "grados_pool_test", &ErasurePoolOptions{PgNum: 8}))
+	defer cluster.DeletePool("erasureTest")
+
+	pool, err := cluster.ManagePool("erasureTest")
+	handleError(t, err)
+	if pool == nil {
+		return
+	}
+	defer pool.Close()
+
+	if !pool.RequiresAlignment() || pool.RequiredAlignment() == 0 {
+		t.Fatal("erasure coded pool should require alignment")
+	}
+	alignment := pool.RequiredAlignment()
+
+	// the default policy rejects misaligned appends
+	if err := pool.ManageObject("misaligned").Append(bytes.NewBufferString("x")); err == nil {
+		t.Error("misaligned append should fail")
+	}
+	if err := NewWriteTransaction().Append(bytes.NewBufferString("x")).Operate(pool.ManageObject("misaligned"), nil); err == nil {
+		t.Error("misaligned append transaction should fail")
+	}
+	wo, err := pool.CreateWriteOperation()
+	handleError(t, err)
+	if wo != nil {
+		if err := wo.Append(bytes.NewBufferString("x")).Operate(pool.ManageObject("misaligned"), nil); err == nil {
+			t.Error("misaligned append operation should fail")
+		}
+		wo.Release()
+	}
+	failed := make(chan error, 1)
+	onError := func(err error, args ...interface{}) { failed <- err }
+	pool.ManageObject("misaligned").AsyncMode(nil, nil, onError).Append(bytes.NewBufferString("x"))
+	select {
+	case <-failed:
+	case <-time.After(5 * time.Second):
+		t.Error("misaligned async append should fail")
+	}
+
+	pool.SetAlignmentPolicy(PadMisaligned)
+	object := pool.ManageObject("padded")
+	handleError(t, object.Append(bytes.NewBufferString("x")))
+	defer object.Remove()
+	status, err := object.Status()
+	handleError(t, err)
+	if status != nil && status.Size() != alignment {
+		t.Errorf("padded object should be %d bytes, is %d", alignment, status.Size())
+	}
+}
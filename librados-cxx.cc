@@ -0,0 +1,108 @@
+#include <algorithm>
+#include <cerrno>
+#include <cstdlib>
+#include <map>
+#include <vector>
+
+#include <rados/librados.hpp>
+
+#include "librados-cxx.h"
+
+extern "C" int grados_sparse_read_op(rados_ioctx_t io, const char *oid, const grados_read_step *steps,
+                                     size_t steps_len, int flags, uint64_t **extents, size_t *extents_len, char **data,
+                                     size_t *data_len) {
+  librados::IoCtx ioctx;
+  librados::IoCtx::from_rados_ioctx_t(io, ioctx);
+
+  std::map<uint64_t, uint64_t> m;
+  librados::bufferlist bl;
+  int read_ret = 0;
+  librados::ObjectReadOperation op;
+  for (size_t i = 0; i < steps_len; i++) {
+    const grados_read_step &step = steps[i];
+    switch (step.kind) {
+    case GRADOS_STEP_ASSERT_EXISTS:
+      op.assert_exists();
+      break;
+    case GRADOS_STEP_CMPXATTR: {
+      librados::bufferlist value;
+      value.append(step.value, step.value_len);
+      op.cmpxattr(step.name, step.op, value);
+      break;
+    }
+    case GRADOS_STEP_SPARSE_READ:
+      op.sparse_read(step.offset, step.length, &m, &bl, &read_ret);
+      break;
+    default:
+      return -EINVAL;
+    }
+    // flags apply to the last operation added
+    if (step.flags != 0) {
+      op.set_op_flags2(step.flags);
+    }
+  }
+  int ret = ioctx.operate(oid, &op, NULL, flags);
+  if (ret < 0) {
+    return ret;
+  }
+  if (read_ret < 0) {
+    return read_ret;
+  }
+
+  *extents_len = m.size();
+  *extents = (uint64_t *)malloc(sizeof(uint64_t) * 2 * (m.size() > 0 ? m.size() : 1));
+  size_t i = 0;
+  for (std::map<uint64_t, uint64_t>::iterator it = m.begin(); it != m.end(); ++it) {
+    (*extents)[i++] = it->first;
+    (*extents)[i++] = it->second;
+  }
+
+  *data_len = bl.length();
+  *data = (char *)malloc(bl.length() > 0 ? bl.length() : 1);
+  if (bl.length() > 0) {
+    bl.copy(0, bl.length(), *data);
+  }
+  return 0;
+}
+
+extern "C" int grados_list_snaps(rados_ioctx_t io, const char *oid, uint64_t **snaps, size_t *snaps_len) {
+  librados::IoCtx ioctx;
+  librados::IoCtx::from_rados_ioctx_t(io, ioctx);
+
+  // Listing clones requires reading at the snapdir. A duplicate is used so the read snapshot of io is left untouched.
+  librados::IoCtx dup;
+  dup.dup(ioctx);
+  dup.snap_set_read(LIBRADOS_SNAP_DIR);
+
+  librados::snap_set_t snap_set;
+  int snap_ret = 0;
+  librados::ObjectReadOperation op;
+  op.list_snaps(&snap_set, &snap_ret);
+  int ret = dup.operate(oid, &op, NULL);
+  if (ret < 0) {
+    return ret;
+  }
+  if (snap_ret < 0) {
+    return snap_ret;
+  }
+
+  std::vector<uint64_t> out;
+  out.push_back(snap_set.seq);
+  out.push_back(snap_set.clones.size());
+  for (std::vector<librados::clone_info_t>::iterator it = snap_set.clones.begin(); it != snap_set.clones.end(); ++it) {
+    out.push_back(it->cloneid);
+    out.push_back(it->size);
+    out.push_back(it->snaps.size());
+    out.insert(out.end(), it->snaps.begin(), it->snaps.end());
+    out.push_back(it->overlap.size());
+    for (std::vector<std::pair<uint64_t, uint64_t> >::iterator o = it->overlap.begin(); o != it->overlap.end(); ++o) {
+      out.push_back(o->first);
+      out.push_back(o->second);
+    }
+  }
+
+  *snaps_len = out.size();
+  *snaps = (uint64_t *)malloc(sizeof(uint64_t) * out.size());
+  std::copy(out.begin(), out.end(), *snaps);
+  return 0;
+}
@@ -0,0 +1,44 @@
+#ifndef GRADOS_LIBRADOS_CXX_H
+#define GRADOS_LIBRADOS_CXX_H
+
+#include <rados/librados.h>
+
+// Functions that are only available in the librados C++ API. Buffers returned through out parameters are allocated with
+// malloc and must be released with free.
+
+#ifdef __cplusplus
+extern "C" {
+#endif
+
+// Kinds of the steps of a sparse read operation.
+#define GRADOS_STEP_ASSERT_EXISTS 0
+#define GRADOS_STEP_CMPXATTR 1
+#define GRADOS_STEP_SPARSE_READ 2
+
+// A step of a sparse read operation. Only the fields relevant to the kind of step are used.
+typedef struct {
+  int kind;
+  int flags;
+  const char *name;
+  uint8_t op;
+  const char *value;
+  size_t value_len;
+  uint64_t offset;
+  uint64_t length;
+} grados_read_step;
+
+// Performs a read operation made of the steps, which must contain exactly one sparse read step. extents is filled with
+// the offset/length pairs of the allocated extents read and data with the content of the extents, in order.
+int grados_sparse_read_op(rados_ioctx_t io, const char *oid, const grados_read_step *steps, size_t steps_len,
+                          int flags, uint64_t **extents, size_t *extents_len, char **data, size_t *data_len);
+
+// Lists the clones of an object. snaps is filled with the seq of the object followed by the number of clones and, for
+// each clone, its id, size, number of snapshots, the snapshot ids, number of overlap extents and the offset/length pairs
+// of the overlap extents.
+int grados_list_snaps(rados_ioctx_t io, const char *oid, uint64_t **snaps, size_t *snaps_len);
+
+#ifdef __cplusplus
+}
+#endif
+
+#endif
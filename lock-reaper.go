@@ -0,0 +1,146 @@
+package grados
+
+import (
+	"context"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// ReapPolicy decides which object locks ReapStaleLocks breaks. The cluster does not track whether a client is alive, so
+// the built-in checks only detect clients that were blacklisted or whose locks expired. Detecting other dead clients,
+// eg. ones running on a crashed node that was not blacklisted, is left to IsStale.
+type ReapPolicy struct {
+	DryRun           bool     // Only report the stale locks without breaking them.
+	BreakBlacklisted bool     // Break locks held by blacklisted clients.
+	BreakExpired     bool     // Break locks whose expiration has passed but are still listed.
+	LockNames        []string // Only consider these locks. All locks are considered if empty.
+
+	// IsStale is called for each locker that is not blacklisted nor expired. If it returns true, the lock is broken
+	// with the returned reason. This is the only way to reap the locks of dead clients that are neither blacklisted nor
+	// expired. This can be nil.
+	IsStale func(object string, info *LockInfo, locker *Locker) (bool, string)
+}
+
+// ReapedLock is a stale lock found by ReapStaleLocks.
+type ReapedLock struct {
+	Object string  // The locked object.
+	Lock   string  // The lock name.
+	Locker *Locker // The stale holder of the lock.
+	Reason string  // Why the lock is considered stale.
+	Broken bool    // True if the lock was broken. Always false in dry run mode.
+	Err    error   // The error if the lock could not be broken.
+}
+
+// ReapError is an error that prevented inspecting the locks of an object.
+type ReapError struct {
+	Object string // The object that could not be inspected.
+	Lock   string // The lock that could not be inspected. Empty if the locks of the object could not be listed.
+	Err    error  // The error.
+}
+
+// ReapReport is the result of ReapStaleLocks. Objects and locks listed in Errors were not inspected, so a report
+// without stale locks is only conclusive if Errors is empty.
+type ReapReport struct {
+	ObjectsScanned int           // The number of objects inspected.
+	Locks          []*ReapedLock // The stale locks found.
+	Errors         []*ReapError  // The objects and locks that could not be inspected.
+}
+
+// ReapStaleLocks scans the objects of the pool and breaks the locks held by clients that are considered dead according
+// to the policy. The report is returned even if the scan is interrupted by an error or by the context.
+func (pool *Pool) ReapStaleLocks(ctx context.Context, policy *ReapPolicy) (*ReapReport, error) {
+	report := &ReapReport{
+		Locks:  make([]*ReapedLock, 0),
+		Errors: make([]*ReapError, 0),
+	}
+
+	blacklisted := make(map[string]bool)
+	if policy.BreakBlacklisted {
+		entries, err := pool.Cluster().ListBlacklist()
+		if err != nil {
+			return report, err
+		}
+		for _, entry := range entries {
+			blacklisted[normalizeAddress(entry.Address)] = true
+		}
+	}
+
+	objects, err := pool.OpenObjectList()
+	if err != nil {
+		return report, err
+	}
+	defer objects.Close()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return report, ctx.Err()
+		default:
+		}
+
+		object, _, err := objects.Next()
+		if err != nil {
+			if radosErr, ok := err.(*RadosError); ok && radosErr.Code == -int(syscall.ENOENT) {
+				return report, nil
+			}
+			return report, err
+		}
+		report.ObjectsScanned++
+
+		locks := policy.LockNames
+		if len(locks) == 0 {
+			if locks, err = object.ListLocks(); err != nil {
+				report.Errors = append(report.Errors, &ReapError{Object: object.name, Err: err})
+				continue
+			}
+		}
+		for _, lock := range locks {
+			info, err := object.ListLockers(lock)
+			if err != nil {
+				report.Errors = append(report.Errors, &ReapError{Object: object.name, Lock: lock, Err: err})
+				continue
+			}
+			for _, locker := range info.Lockers {
+				stale, reason := policy.stale(object.name, info, locker, blacklisted)
+				if !stale {
+					continue
+				}
+				reaped := &ReapedLock{
+					Object: object.name,
+					Lock:   lock,
+					Locker: locker,
+					Reason: reason,
+				}
+				if !policy.DryRun {
+					reaped.Err = object.BreakLock(lock, locker.Client, locker.Cookie)
+					reaped.Broken = reaped.Err == nil
+				}
+				report.Locks = append(report.Locks, reaped)
+			}
+		}
+	}
+}
+
+// stale returns true and the reason if the locker should be reaped.
+func (policy *ReapPolicy) stale(object string, info *LockInfo, locker *Locker, blacklisted map[string]bool) (bool, string) {
+	if policy.BreakBlacklisted && blacklisted[normalizeAddress(locker.Address)] {
+		return true, "client is blacklisted"
+	}
+	if policy.BreakExpired && !locker.Expiration.IsZero() && locker.Expiration.Before(time.Now()) {
+		return true, "lock is expired"
+	}
+	if policy.IsStale != nil {
+		return policy.IsStale(object, info, locker)
+	}
+	return false, ""
+}
+
+// normalizeAddress strips the protocol prefix of newer address formats (eg. v1:10.0.0.1:0/1234) so addresses can be
+// compared.
+func normalizeAddress(address string) string {
+	for _, prefix := range []string{"v1:", "v2:", "any:"} {
+		address = strings.TrimPrefix(address, prefix)
+	}
+	return address
+}
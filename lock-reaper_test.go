@@ -0,0 +1,81 @@
+package grados
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+)
+
+func TestReapPolicy(t *testing.T) {
+	policy := &ReapPolicy{
+		BreakBlacklisted: true,
+		BreakExpired:     true,
+	}
+	blacklisted := map[string]bool{"10.0.0.1:0/1234": true}
+	info := &LockInfo{Name: "lock", Type: ExclusiveLock}
+
+	locker := &Locker{Client: "client.1", Cookie: "cookie", Address: "v1:10.0.0.1:0/1234"}
+	if stale, _ := policy.stale("object", info, locker, blacklisted); !stale {
+		t.Error("blacklisted locker should be stale")
+	}
+
+	locker = &Locker{Client: "client.2", Cookie: "cookie", Address: "10.0.0.2:0/1234", Expiration: time.Now().Add(-time.Minute)}
+	if stale, _ := policy.stale("object", info, locker, blacklisted); !stale {
+		t.Error("expired locker should be stale")
+	}
+
+	locker = &Locker{Client: "client.3", Cookie: "cookie", Address: "10.0.0.3:0/1234"}
+	if stale, _ := policy.stale("object", info, locker, blacklisted); stale {
+		t.Error("live locker should not be stale")
+	}
+}
+
+func TestReapStaleLocksDryRun(t *testing.T) {
+	cluster := connect(t)
+	if cluster == nil {
+		return
+	}
+	defer cluster.Shutdown()
+
+	pool, err := cluster.ManagePool("data")
+	handleError(t, err)
+	if pool == nil {
+		return
+	}
+	defer pool.Close()
+
+	object := pool.ManageObject("reaper_object")
+	err = object.WriteFull(bytes.NewBufferString("data"))
+	handleError(t, err)
+	defer object.Remove()
+	handleError(t, object.LockExclusive("reaper", "dead", "", 0))
+	defer object.Unlock("reaper", "dead")
+
+	policy := &ReapPolicy{
+		DryRun: true,
+		IsStale: func(object string, info *LockInfo, locker *Locker) (bool, string) {
+			return locker.Cookie == "dead", "cookie is dead"
+		},
+	}
+	report, err := pool.ReapStaleLocks(context.Background(), policy)
+	handleError(t, err)
+	if report == nil {
+		return
+	}
+	found := false
+	for _, lock := range report.Locks {
+		if lock.Object == "reaper_object" && lock.Lock == "reaper" {
+			found = true
+			if lock.Broken {
+				t.Error("lock should not be broken in dry run")
+			}
+		}
+	}
+	if !found {
+		t.Error("stale lock should be reported")
+	}
+	for _, reapErr := range report.Errors {
+		t.Errorf("%s %s could not be inspected: %s", reapErr.Object, reapErr.Lock, reapErr.Err)
+	}
+}
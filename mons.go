@@ -6,6 +6,13 @@ package grados
 */
 import "C"
 
+import (
+	"encoding/json"
+	"fmt"
+	"syscall"
+	"unsafe"
+)
+
 // PingMonitor will query the given monitor to check it's status
 // TODO: Make struct for result
 func (cluster *Cluster) PingMonitor(monitorId string) (string, error) {
@@ -26,3 +33,61 @@ func (cluster *Cluster) PingMonitor(monitorId string) (string, error) {
 	result := C.GoStringN(out, (C.int)(outLen))
 	return result, nil
 }
+
+// MonCommand sends a command to the monitors and returns the command output and status message. The command is a JSON
+// object such as {"prefix": "osd pool get", "pool": "data", "var": "size", "format": "json"}. The input is passed to
+// commands that read data (eg. setting a crush map) and can be nil.
+func (cluster *Cluster) MonCommand(command string, input []byte) ([]byte, string, error) {
+	cmd := C.CString(command)
+	defer freeString(cmd)
+	cmds := []*C.char{cmd}
+
+	var in *C.char
+	if len(input) > 0 {
+		in = (*C.char)(unsafe.Pointer(&input[0]))
+	}
+
+	var out, status *C.char
+	var outLen, statusLen C.size_t
+
+	ret := C.rados_mon_command(cluster.handle, &cmds[0], 1, in, C.size_t(len(input)), &out, &outLen, &status, &statusLen)
+	if out != nil {
+		defer C.rados_buffer_free(out)
+	}
+	if status != nil {
+		defer C.rados_buffer_free(status)
+	}
+
+	statusMessage := C.GoStringN(status, C.int(statusLen))
+	if err := toRadosError(ret); err != nil {
+		err.Message = fmt.Sprintf("Unable to run mon command %s. %s", command, statusMessage)
+		return nil, statusMessage, err
+	}
+	return C.GoBytes(unsafe.Pointer(out), C.int(outLen)), statusMessage, nil
+}
+
+// monCommand runs the mon command described by args. If result is not nil, the JSON output of the command is decoded
+// into it.
+func (cluster *Cluster) monCommand(args map[string]interface{}, result interface{}) error {
+	if result != nil {
+		args["format"] = "json"
+	}
+	command, err := json.Marshal(args)
+	if err != nil {
+		return err
+	}
+	out, _, err := cluster.MonCommand(string(command), nil)
+	if err != nil {
+		return err
+	}
+	if result == nil || len(out) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(out, result); err != nil {
+		return &RadosError{
+			Code:    -int(syscall.EINVAL),
+			Message: fmt.Sprintf("Unable to decode output of mon command %s. %s", command, err),
+		}
+	}
+	return nil
+}
@@ -7,8 +7,10 @@ package grados
 import "C"
 
 import (
+	"bytes"
 	"fmt"
 	"io"
+	"syscall"
 )
 
 // AsyncIoCallback is the signature of a callback function that can be used on asynchronous operations. This can receive
@@ -30,6 +32,7 @@ type AsyncObject struct {
 	onSafe     AsyncIoCallback
 	onError    ASyncIoErrorCallback
 	args       []interface{}
+	alignment  AlignmentPolicy
 }
 
 // AsyncMode Prepares the object for asynchronous operations. If callbacks are set to nil, the results will be ignored.
@@ -40,7 +43,9 @@ func (o *Object) AsyncMode(onComplete, onSafe AsyncIoCallback, onError ASyncIoEr
 		name:       o.name,
 		onComplete: onComplete,
 		onSafe:     onSafe,
+		onError:    onError,
 		args:       args,
+		alignment:  o.alignment,
 	}
 	C.rados_aio_create_completion(nil, nil, nil, &a.completion)
 	return a
@@ -81,12 +86,19 @@ func (ao *AsyncObject) WriteFull(data io.Reader) {
 	}()
 }
 
-// Append performs an append operation asynchronously.
+// Append performs an append operation asynchronously. The alignment policy of the pool is applied to the data, and
+// appends it rejects are reported to the onError callback without being sent.
 func (ao *AsyncObject) Append(data io.Reader) {
 	go func() {
+		buf := readAll(data)
+		aligned, alignment, ok := alignAppend(ao.ioContext, ao.alignment, buf)
+		if !ok {
+			ao.processError(-C.int(syscall.EINVAL), fmt.Sprintf("Unable to append %d bytes to object %s. The pool requires appends aligned to %d bytes.", len(buf), ao.name, alignment))
+			return
+		}
 		oid := C.CString(ao.name)
 		defer freeString(oid)
-		bufAddr, bufLen := readerToBuf(data)
+		bufAddr, bufLen := readerToBuf(bytes.NewReader(aligned))
 		ret := C.rados_aio_append(ao.ioContext, oid, ao.completion, bufAddr, C.size_t(bufLen))
 		hasErr := ao.processError(ret, fmt.Sprintf("Unable to append to object %s", ao.name))
 		if !hasErr {
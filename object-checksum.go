@@ -0,0 +1,134 @@
+package grados
+
+/*
+#cgo LDFLAGS: -lrados
+#include <rados/librados.h>
+*/
+import "C"
+
+import (
+	"encoding/binary"
+	"fmt"
+	"syscall"
+	"unsafe"
+)
+
+// checksumResult holds the output of a checksum step of a read operation.
+type checksumResult struct {
+	kind   ChecksumType
+	buffer []byte
+	retVal C.int
+}
+
+// Checksum adds a step that computes checksums of a length of the object starting at the given offset without reading
+// the data. The range is split into chunks of chunkSize bytes and a checksum is computed for each chunk. A chunkSize of
+// 0 computes a single checksum for the whole range, and an offset and length of 0 covers the whole object. Use
+// Checksums to retrieve the result after calling Operate.
+func (ro *ReadOperation) Checksum(kind ChecksumType, offset, length, chunkSize uint64) *ReadOperation {
+	return ro.checksumWithBuffer(kind, offset, length, chunkSize, checksumBufferLength(kind, length, chunkSize))
+}
+
+// checksumWithBuffer adds a checksum step using a result buffer of bufLen bytes.
+func (ro *ReadOperation) checksumWithBuffer(kind ChecksumType, offset, length, chunkSize uint64, bufLen int) *ReadOperation {
+	ro.checksum = &checksumResult{
+		kind:   kind,
+		buffer: make([]byte, bufLen),
+	}
+	init := checksumInitValue(kind)
+	C.rados_read_op_checksum(ro.opContext, C.rados_checksum_type_t(kind),
+		(*C.char)(unsafe.Pointer(&init[0])), C.size_t(len(init)),
+		C.uint64_t(offset), C.size_t(length), C.size_t(chunkSize),
+		(*C.char)(unsafe.Pointer(&ro.checksum.buffer[0])), C.size_t(len(ro.checksum.buffer)),
+		&ro.checksum.retVal)
+	return ro
+}
+
+// Checksums returns the per chunk checksums computed by the Checksum step. This should be called after Operate.
+func (ro *ReadOperation) Checksums() ([]uint64, error) {
+	if ro.checksum == nil {
+		err := toRadosError(-1)
+		err.Message = "No checksum step in read operation."
+		return nil, err
+	}
+	if err := toRadosError(ro.checksum.retVal); err != nil {
+		err.Message = "Unable to compute checksums."
+		return nil, err
+	}
+	return ro.checksum.values()
+}
+
+// Checksum computes checksums of a length of the object starting at the given offset. The computation is done by the
+// OSDs so the data is not transferred. See ReadOperation.Checksum for the meaning of the arguments.
+func (o *Object) Checksum(kind ChecksumType, offset, length, chunkSize uint64) ([]uint64, error) {
+	size := checksumBufferLength(kind, length, chunkSize)
+	for {
+		ro, err := newReadOperation(o.ioContext)
+		if err != nil {
+			return nil, err
+		}
+		ro.checksumWithBuffer(kind, offset, length, chunkSize, size)
+		_, err = ro.Operate(o)
+		ro.Release()
+		if err, ok := err.(*RadosError); ok && err.Code == -int(syscall.ERANGE) {
+			size *= 2
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		return ro.checksum.values()
+	}
+}
+
+// values decodes the checksum buffer. The buffer contains a little endian 32 bit count followed by the checksums.
+func (result *checksumResult) values() ([]uint64, error) {
+	valueSize := checksumValueSize(result.kind)
+	if len(result.buffer) < 4 {
+		err := toRadosError(-1)
+		err.Message = "Checksum result is too short."
+		return nil, err
+	}
+	count := int(binary.LittleEndian.Uint32(result.buffer))
+	if len(result.buffer) < 4+count*valueSize {
+		err := toRadosError(-1)
+		err.Message = fmt.Sprintf("Checksum result is too short for %d checksums.", count)
+		return nil, err
+	}
+	checksums := make([]uint64, count)
+	for i := range checksums {
+		value := result.buffer[4+i*valueSize:]
+		if valueSize == 8 {
+			checksums[i] = binary.LittleEndian.Uint64(value)
+		} else {
+			checksums[i] = uint64(binary.LittleEndian.Uint32(value))
+		}
+	}
+	return checksums, nil
+}
+
+// checksumValueSize returns the size in bytes of a single checksum value.
+func checksumValueSize(kind ChecksumType) int {
+	if kind == ChecksumXXHash64 {
+		return 8
+	}
+	return 4
+}
+
+// checksumInitValue returns the seed used for the checksum type.
+func checksumInitValue(kind ChecksumType) []byte {
+	init := make([]byte, checksumValueSize(kind))
+	if kind == ChecksumCRC32C {
+		binary.LittleEndian.PutUint32(init, 0xffffffff)
+	}
+	return init
+}
+
+// checksumBufferLength returns the buffer size needed for the checksums of the range. When the number of chunks can't be
+// known in advance, a buffer for a single chunk is returned and callers should grow it on ERANGE.
+func checksumBufferLength(kind ChecksumType, length, chunkSize uint64) int {
+	chunks := uint64(1)
+	if length > 0 && chunkSize > 0 {
+		chunks = (length + chunkSize - 1) / chunkSize
+	}
+	return 4 + int(chunks)*checksumValueSize(kind)
+}
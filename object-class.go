@@ -0,0 +1,45 @@
+package grados
+
+/*
+#cgo LDFLAGS: -lrados
+#include <rados/librados.h>
+*/
+import "C"
+
+import (
+	"fmt"
+	"syscall"
+	"unsafe"
+)
+
+// execute calls a method of an object class on the object and returns its output.
+func (o *Object) execute(class, method string, input []byte) ([]byte, error) {
+	oid := C.CString(o.name)
+	defer freeString(oid)
+
+	c := C.CString(class)
+	defer freeString(c)
+
+	m := C.CString(method)
+	defer freeString(m)
+
+	var in *C.char
+	if len(input) > 0 {
+		in = (*C.char)(unsafe.Pointer(&input[0]))
+	}
+
+	bufLen := 4096
+	for {
+		bufAddr := bufferAddress(bufLen)
+		ret := C.rados_exec(o.ioContext, oid, c, m, in, C.size_t(len(input)), bufAddr, C.size_t(bufLen))
+		if int(ret) == -int(syscall.ERANGE) {
+			bufLen *= 2
+			continue
+		}
+		if err := toRadosError(ret); err != nil {
+			err.Message = fmt.Sprintf("Unable to execute %s.%s on object %s.", class, method, o.name)
+			return nil, err
+		}
+		return C.GoBytes(unsafe.Pointer(bufAddr), ret), nil
+	}
+}
@@ -0,0 +1,163 @@
+package grados
+
+import (
+	"bytes"
+	"sync"
+	"time"
+)
+
+const (
+	electionLock      = "grados.election"        // The lock contended by the candidates.
+	electionAttribute = "grados.election.leader" // The attribute the leader publishes its identity in.
+)
+
+// Election is a leader election among the clients campaigning on the same object. Candidates contend for a timed
+// exclusive lock on the object. The winner keeps the lock with a Lease, publishes its identity in an extended attribute
+// of the object for other readers and notifies the watchers of the object. Use Campaign from an object to create a
+// valid instance.
+type Election struct {
+	object   *Object
+	identity string
+	ttl      time.Duration
+	watch    *ResilientWatch
+	lease    *Lease
+
+	mutex   sync.RWMutex
+	leader  string
+	changes chan string
+
+	done      chan struct{}
+	closeOnce sync.Once
+	wg        sync.WaitGroup
+}
+
+// Campaign joins the leader election held on the object using the given identity. The identity must be unique among the
+// candidates. The leader holds the election lock for the ttl duration and keeps renewing it, so a crashed leader is
+// replaced after ttl at most. The ttl must be at least one second. The object is created if it does not exist.
+func (o *Object) Campaign(identity string, ttl time.Duration) (*Election, error) {
+	if _, err := leaseInterval(ttl); err != nil {
+		return nil, err
+	}
+	if err := NewWriteTransaction().CreateObject(CreateIdempotent, "").Operate(o, nil); err != nil {
+		return nil, err
+	}
+	watch, err := o.WatchResilient()
+	if err != nil {
+		return nil, err
+	}
+	e := &Election{
+		object:   o,
+		identity: identity,
+		ttl:      ttl,
+		watch:    watch,
+		changes:  make(chan string, 1),
+		done:     make(chan struct{}),
+	}
+	e.wg.Add(1)
+	go e.run()
+	return e, nil
+}
+
+// Leader returns the identity of the current leader. This returns an empty string if there is no leader.
+func (e *Election) Leader() string {
+	e.mutex.RLock()
+	defer e.mutex.RUnlock()
+	return e.leader
+}
+
+// IsLeader returns true if this candidate is the leader.
+func (e *Election) IsLeader() bool {
+	return e.Leader() == e.identity
+}
+
+// Changes returns a channel receiving the identity of the leader every time it changes. Only the latest change is kept
+// if the channel is not read fast enough.
+func (e *Election) Changes() <-chan string {
+	return e.changes
+}
+
+// Close leaves the election. The leadership is released if this candidate is the leader.
+func (e *Election) Close() error {
+	var err error
+	e.closeOnce.Do(func() {
+		close(e.done)
+		e.wg.Wait()
+		if e.lease != nil {
+			err = e.lease.Close()
+			e.object.Notify(bytes.NewBufferString(""), e.ttl/3)
+		}
+		if werr := e.watch.Close(); err == nil {
+			err = werr
+		}
+	})
+	return err
+}
+
+// run campaigns until the election is closed.
+func (e *Election) run() {
+	defer e.wg.Done()
+	ticker := time.NewTicker(e.ttl / 3)
+	defer ticker.Stop()
+	e.campaign()
+	for {
+		var lost <-chan struct{}
+		if e.lease != nil {
+			lost = e.lease.Lost()
+		}
+		select {
+		case <-e.done:
+			return
+		case <-e.watch.Events():
+			e.refresh()
+		case <-lost:
+			e.lease.Close()
+			e.lease = nil
+			e.refresh()
+		case <-ticker.C:
+			e.campaign()
+		}
+	}
+}
+
+// campaign tries to become the leader if there is none.
+func (e *Election) campaign() {
+	if e.lease == nil {
+		lease, err := e.object.AcquireLease(electionLock, e.identity, "grados leader election", e.ttl)
+		if err == nil {
+			e.lease = lease
+			e.object.SetAttribute(electionAttribute, bytes.NewBufferString(e.identity))
+			e.object.Notify(bytes.NewBufferString(e.identity), e.ttl/3)
+		}
+	}
+	e.refresh()
+}
+
+// refresh looks up the leader. The holder of the election lock is used rather than the published attribute since the
+// attribute is stale until a new leader publishes itself. There is no leader if nobody holds the election lock.
+func (e *Election) refresh() {
+	info, err := e.object.ListLockers(electionLock)
+	if err != nil {
+		return
+	}
+	leader := ""
+	if len(info.Lockers) > 0 {
+		leader = info.Lockers[0].Cookie
+	}
+	e.setLeader(leader)
+}
+
+// setLeader updates the leader and publishes the change.
+func (e *Election) setLeader(leader string) {
+	e.mutex.Lock()
+	changed := e.leader != leader
+	e.leader = leader
+	e.mutex.Unlock()
+	if !changed {
+		return
+	}
+	select {
+	case <-e.changes:
+	default:
+	}
+	e.changes <- leader
+}
@@ -0,0 +1,129 @@
+package grados
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+func TestElection(t *testing.T) {
+	cluster := connect(t)
+	if cluster == nil {
+		return
+	}
+	defer cluster.Shutdown()
+
+	pool, err := cluster.ManagePool("data")
+	handleError(t, err)
+	if pool == nil {
+		return
+	}
+	defer pool.Close()
+
+	object := pool.ManageObject("election_object")
+	defer object.Remove()
+
+	first, err := object.Campaign("first", 3*time.Second)
+	handleError(t, err)
+	if first == nil {
+		return
+	}
+	if leader := <-first.Changes(); leader != "first" {
+		t.Errorf("leader should be first, leader is %s", leader)
+	}
+
+	second, err := object.Campaign("second", 3*time.Second)
+	handleError(t, err)
+	if second == nil {
+		return
+	}
+	if leader := <-second.Changes(); leader != "first" {
+		t.Errorf("leader should be first, leader is %s", leader)
+	}
+
+	handleError(t, first.Close())
+	select {
+	case leader := <-second.Changes():
+		if leader == "" {
+			leader = <-second.Changes()
+		}
+		if leader != "second" {
+			t.Errorf("leader should be second, leader is %s", leader)
+		}
+	case <-time.After(10 * time.Second):
+		t.Error("second should have been elected")
+	}
+	handleError(t, second.Close())
+}
+
+func TestDecodeNotifyReply(t *testing.T) {
+	buf := []byte{
+		1, 0, 0, 0, // 1 ack
+		1, 0, 0, 0, 0, 0, 0, 0, // client id
+		2, 0, 0, 0, 0, 0, 0, 0, // cookie
+		2, 0, 0, 0, 'o', 'k', // reply
+		1, 0, 0, 0, // 1 timeout
+		3, 0, 0, 0, 0, 0, 0, 0, // client id
+		4, 0, 0, 0, 0, 0, 0, 0, // cookie
+	}
+	result := decodeNotifyReply(buf)
+	if len(result.Acks) != 1 || len(result.Timeouts) != 1 {
+		t.Errorf("should have 1 ack and 1 timeout, has %d and %d", len(result.Acks), len(result.Timeouts))
+		return
+	}
+	ack := result.Acks[0]
+	if ack.ClientId != 1 || ack.Cookie != 2 || string(ack.Reply) != "ok" {
+		t.Errorf("unexpected ack %+v", ack)
+	}
+	timeout := result.Timeouts[0]
+	if timeout.ClientId != 3 || timeout.Cookie != 4 {
+		t.Errorf("unexpected timeout %+v", timeout)
+	}
+}
+
+func TestWatchDropsWhenFull(t *testing.T) {
+	cluster := connect(t)
+	if cluster == nil {
+		return
+	}
+	defer cluster.Shutdown()
+
+	pool, err := cluster.ManagePool("data")
+	handleError(t, err)
+	if pool == nil {
+		return
+	}
+	defer pool.Close()
+
+	object := pool.ManageObject("watch_drops")
+	handleError(t, object.WriteFull(bytes.NewBufferString("")))
+	defer object.Remove()
+
+	watch, err := object.Watch()
+	handleError(t, err)
+	if watch == nil {
+		return
+	}
+	defer watch.Close()
+
+	// nobody reads the notifications, so the ones past the buffer are dropped without blocking the notifies
+	for i := 0; i < 70; i++ {
+		_, err := object.Notify(bytes.NewBufferString("hello"), 5*time.Second)
+		handleError(t, err)
+	}
+	select {
+	case <-watch.Dropped():
+	case <-time.After(5 * time.Second):
+		t.Error("dropped notifications should have been reported")
+	}
+	if len(watch.Notifications()) != 64 {
+		t.Errorf("notifications channel should be full, has %d", len(watch.Notifications()))
+	}
+}
+
+func TestCampaignTTL(t *testing.T) {
+	// the ttl is checked before the object is used
+	if _, err := (&Object{name: "election"}).Campaign("candidate", 3*time.Millisecond); err == nil {
+		t.Error("ttl shorter than 1s should be rejected")
+	}
+}
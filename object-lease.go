@@ -0,0 +1,185 @@
+package grados
+
+import (
+	"fmt"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// Lease is an exclusive object lock that is renewed in the background until it is closed. If the lock can't be renewed
+// before it expires, the lease is lost and the channel returned by Lost is closed. Expiry is measured locally from the
+// start of the last successful lock or renewal, so the lease is lost on time even if the cluster stops answering. Use
+// AcquireLease from an object to create a valid instance.
+type Lease struct {
+	object      *Object
+	name        string
+	cookie      string
+	description string
+	ttl         time.Duration
+	interval    time.Duration
+
+	lost      chan struct{}
+	lostOnce  sync.Once
+	done      chan struct{}
+	closeOnce sync.Once
+	wg        sync.WaitGroup
+}
+
+// AcquireLease exclusively locks the object for the ttl duration and keeps renewing the lock every third of the ttl.
+// The cookie identifies the lease holder and should be unique among clients contending for the lock.
+func (o *Object) AcquireLease(name, cookie, description string, ttl time.Duration) (*Lease, error) {
+	interval, err := leaseInterval(ttl)
+	if err != nil {
+		return nil, err
+	}
+	acquired := time.Now()
+	if err := o.LockExclusive(name, cookie, description, ttl); err != nil {
+		return nil, err
+	}
+	lease := &Lease{
+		object:      o,
+		name:        name,
+		cookie:      cookie,
+		description: description,
+		ttl:         ttl,
+		interval:    interval,
+		lost:        make(chan struct{}),
+		done:        make(chan struct{}),
+	}
+	lease.wg.Add(1)
+	go lease.renew(acquired)
+	return lease, nil
+}
+
+// minLeaseTTL is the shortest lease duration. Shorter leases would be renewed more often than the cluster can answer.
+const minLeaseTTL = time.Second
+
+// leaseInterval returns how often a lock held for ttl is renewed. An error is returned if the ttl is shorter than
+// minLeaseTTL.
+func leaseInterval(ttl time.Duration) (time.Duration, error) {
+	if ttl < minLeaseTTL {
+		return 0, &RadosError{
+			Code:    -int(syscall.EINVAL),
+			Message: fmt.Sprintf("Lease duration must be at least %v, is %v.", minLeaseTTL, ttl),
+		}
+	}
+	return ttl / 3, nil
+}
+
+// Lost returns a channel that is closed when the lease could not be renewed. The lock should be considered held by
+// someone else once this happens.
+func (lease *Lease) Lost() <-chan struct{} {
+	return lease.lost
+}
+
+// IsLost returns true if the lease was lost.
+func (lease *Lease) IsLost() bool {
+	select {
+	case <-lease.lost:
+		return true
+	default:
+		return false
+	}
+}
+
+// Close stops renewing the lease and releases the lock. Closing a lost lease does not unlock the object.
+func (lease *Lease) Close() error {
+	var err error
+	lease.closeOnce.Do(func() {
+		close(lease.done)
+		lease.wg.Wait()
+		if !lease.IsLost() {
+			err = lease.object.Unlock(lease.name, lease.cookie)
+		}
+	})
+	return err
+}
+
+// leaseRenewal is the result of a renewal of a lease.
+type leaseRenewal struct {
+	start  time.Time // When the renewal was sent.
+	err    error     // The error of the renewal.
+	stolen bool      // The lock is confirmed to be held by someone else.
+}
+
+// renew renews the lock until the lease is closed or lost. Renewals run in the background so the lease is lost once
+// the lock expires even if a renewal blocks. Failed renewals are retried until the lock expires unless the lock is
+// confirmed to be held by someone else. A renewal sent after the lock expired does not recover the lease since the lock
+// may have been held by someone else in the meantime.
+func (lease *Lease) renew(acquired time.Time) {
+	defer lease.wg.Done()
+	expires := acquired.Add(lease.ttl)
+	deadline := time.NewTimer(time.Until(expires))
+	defer deadline.Stop()
+	ticker := time.NewTicker(lease.interval)
+	defer ticker.Stop()
+	lose := func() { lease.lostOnce.Do(func() { close(lease.lost) }) }
+
+	renewals := make(chan *leaseRenewal, 1)
+	renewing := false
+	for {
+		select {
+		case <-lease.done:
+			// wait for the renewal in flight so it doesn't lock the object again after Close unlocks it
+			if renewing {
+				select {
+				case <-renewals:
+				case <-deadline.C:
+					lose()
+				}
+			}
+			return
+		case <-deadline.C:
+			lose()
+			return
+		case <-ticker.C:
+			if !renewing {
+				renewing = true
+				go lease.renewOnce(renewals)
+			}
+		case renewal := <-renewals:
+			renewing = false
+			if renewal.stolen || (renewal.err == nil && !renewal.start.Before(expires)) {
+				lose()
+				return
+			}
+			if renewal.err == nil {
+				expires = renewal.start.Add(lease.ttl)
+				if !deadline.Stop() {
+					select {
+					case <-deadline.C:
+					default:
+					}
+				}
+				deadline.Reset(time.Until(expires))
+			}
+		}
+	}
+}
+
+// renewOnce renews the lock and sends the result to renewals. If the renewal fails, the lockers of the object are
+// checked to see if the lock is held by someone else.
+func (lease *Lease) renewOnce(renewals chan<- *leaseRenewal) {
+	renewal := &leaseRenewal{start: time.Now()}
+	renewal.err = lease.object.RenewLockExclusive(lease.name, lease.cookie, lease.description, lease.ttl)
+	if renewal.err != nil {
+		held, err := lease.held()
+		renewal.stolen = err == nil && !held
+	}
+	renewals <- renewal
+}
+
+// held checks the lockers of the object to see if the lease still holds the lock.
+func (lease *Lease) held() (bool, error) {
+	info, err := lease.object.ListLockers(lease.name)
+	if err != nil {
+		return false, err
+	}
+	for _, locker := range info.Lockers {
+		if locker.Cookie == lease.cookie {
+			return true, nil
+		}
+	}
+	return false, nil
+}
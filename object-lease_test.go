@@ -0,0 +1,59 @@
+package grados
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+func TestLease(t *testing.T) {
+	cluster := connect(t)
+	if cluster == nil {
+		return
+	}
+	defer cluster.Shutdown()
+
+	pool, err := cluster.ManagePool("data")
+	handleError(t, err)
+	if pool == nil {
+		return
+	}
+	defer pool.Close()
+
+	object := pool.ManageObject("lease_object")
+	err = object.WriteFull(bytes.NewBufferString("data"))
+	handleError(t, err)
+	defer object.Remove()
+
+	lease, err := object.AcquireLease("lease", "holder", "lease test", 2*time.Second)
+	handleError(t, err)
+	if lease == nil {
+		return
+	}
+
+	// the lease should outlive its ttl while it is renewed
+	time.Sleep(3 * time.Second)
+	if lease.IsLost() {
+		t.Error("lease should not be lost")
+	}
+	if err := object.LockExclusive("lease", "other", "lease test", time.Second); err == nil {
+		t.Error("lock should still be held by the lease")
+	}
+
+	err = lease.Close()
+	handleError(t, err)
+	err = object.LockExclusive("lease", "other", "lease test", time.Second)
+	handleError(t, err)
+}
+
+func TestLeaseInterval(t *testing.T) {
+	if _, err := leaseInterval(3 * time.Nanosecond); err == nil {
+		t.Error("ttl of 3ns should be rejected")
+	}
+	if _, err := leaseInterval(999 * time.Millisecond); err == nil {
+		t.Error("ttl shorter than 1s should be rejected")
+	}
+	if interval, err := leaseInterval(3 * time.Second); err != nil || interval != time.Second {
+		t.Errorf("interval of 3s ttl should be 1s, is %v (%v)", interval, err)
+	}
+}
@@ -0,0 +1,113 @@
+package grados
+
+/*
+#cgo LDFLAGS: -lrados
+#include <rados/librados.h>
+*/
+import "C"
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"syscall"
+	"time"
+)
+
+const (
+	mutexMinBackoff = 10 * time.Millisecond // The first wait when the lock is busy.
+	mutexMaxBackoff = time.Second           // The longest wait between lock attempts.
+)
+
+// DistributedMutex is a mutual exclusion lock shared by every client of the cluster. It is backed by an exclusive lock
+// on an object and implements sync.Locker. Use NewDistributedMutex from an object to create a valid instance.
+//
+// The lock cookie is derived from the cluster instance id so goroutines of the same process contend for the lock like
+// any other client.
+type DistributedMutex struct {
+	object   *Object
+	name     string
+	cookie   string
+	duration time.Duration
+}
+
+// NewDistributedMutex creates a mutex using the named lock of the object. If duration is greater than 0, the lock
+// expires after that duration so a crashed holder does not keep it forever. Critical sections must be shorter than the
+// duration in that case.
+func (o *Object) NewDistributedMutex(name string, duration time.Duration) *DistributedMutex {
+	cluster := &Cluster{
+		handle: C.rados_ioctx_get_cluster(o.ioContext),
+	}
+	return &DistributedMutex{
+		object:   o,
+		name:     name,
+		cookie:   fmt.Sprintf("grados-%d", cluster.InstanceId()),
+		duration: duration,
+	}
+}
+
+// Cookie returns the lock cookie used by the mutex.
+func (m *DistributedMutex) Cookie() string {
+	return m.cookie
+}
+
+// TryLock attempts to lock the mutex without waiting. This returns false if the lock is held, by this process or any
+// other client.
+func (m *DistributedMutex) TryLock() (bool, error) {
+	err := m.object.LockExclusive(m.name, m.cookie, "grados distributed mutex", m.duration)
+	if err == nil {
+		return true, nil
+	}
+	if isLockBusy(err) {
+		return false, nil
+	}
+	return false, err
+}
+
+// LockContext locks the mutex, waiting with an exponential backoff while the lock is held. This returns the context
+// error if the context is done before the lock is acquired.
+func (m *DistributedMutex) LockContext(ctx context.Context) error {
+	backoff := mutexMinBackoff
+	for {
+		locked, err := m.TryLock()
+		if err != nil {
+			return err
+		}
+		if locked {
+			return nil
+		}
+		wait := backoff/2 + time.Duration(rand.Int63n(int64(backoff/2)+1))
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(wait):
+		}
+		if backoff *= 2; backoff > mutexMaxBackoff {
+			backoff = mutexMaxBackoff
+		}
+	}
+}
+
+// Lock locks the mutex, blocking until it is available. Like sync.Mutex, this panics if the lock can't be acquired
+// because of an error other than the lock being held.
+func (m *DistributedMutex) Lock() {
+	if err := m.LockContext(context.Background()); err != nil {
+		panic(fmt.Sprintf("grados: unable to lock distributed mutex: %s", err))
+	}
+}
+
+// Unlock unlocks the mutex. Like sync.Mutex, this panics if the mutex is not locked.
+func (m *DistributedMutex) Unlock() {
+	if err := m.object.Unlock(m.name, m.cookie); err != nil {
+		panic(fmt.Sprintf("grados: unable to unlock distributed mutex: %s", err))
+	}
+}
+
+// isLockBusy returns true if the error is returned because the lock is already held.
+func isLockBusy(err error) bool {
+	radosErr, ok := err.(*RadosError)
+	if !ok {
+		return false
+	}
+	return radosErr.Code == -int(syscall.EBUSY) || radosErr.Code == -int(syscall.EEXIST)
+}
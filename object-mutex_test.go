@@ -0,0 +1,54 @@
+package grados
+
+import (
+	"bytes"
+	"context"
+	"sync"
+	"testing"
+	"time"
+)
+
+var _ sync.Locker = (*DistributedMutex)(nil)
+
+func TestDistributedMutex(t *testing.T) {
+	cluster := connect(t)
+	if cluster == nil {
+		return
+	}
+	defer cluster.Shutdown()
+
+	pool, err := cluster.ManagePool("data")
+	handleError(t, err)
+	if pool == nil {
+		return
+	}
+	defer pool.Close()
+
+	object := pool.ManageObject("mutex_object")
+	err = object.WriteFull(bytes.NewBufferString("data"))
+	handleError(t, err)
+	defer object.Remove()
+
+	mutex := object.NewDistributedMutex("mutex", 10*time.Second)
+	mutex.Lock()
+
+	locked, err := mutex.TryLock()
+	handleError(t, err)
+	if locked {
+		t.Error("mutex should already be locked")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	if err := mutex.LockContext(ctx); err != context.DeadlineExceeded {
+		t.Errorf("should time out, error is %v", err)
+	}
+
+	mutex.Unlock()
+	locked, err = mutex.TryLock()
+	handleError(t, err)
+	if !locked {
+		t.Error("mutex should be unlocked")
+	}
+	mutex.Unlock()
+}
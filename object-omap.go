@@ -0,0 +1,123 @@
+package grados
+
+/*
+#cgo LDFLAGS: -lrados
+#include <stdlib.h>
+#include <rados/librados.h>
+*/
+import "C"
+
+import (
+	"fmt"
+	"unsafe"
+)
+
+// omapPageSize is the number of omap entries fetched per read operation.
+const omapPageSize = 512
+
+// updateOmap sets and removes omap entries of the object in a single atomic operation. The object is created if it
+// does not exist.
+func (o *Object) updateOmap(set map[string][]byte, remove []string) error {
+	wo, err := newWriteOperation(o.ioContext, o.alignment)
+	if err != nil {
+		return err
+	}
+	defer wo.Release()
+
+	cStrings := make([]*C.char, 0, 2*len(set)+len(remove))
+	defer func() {
+		for _, s := range cStrings {
+			C.free(unsafe.Pointer(s))
+		}
+	}()
+
+	if len(set) > 0 {
+		keys := make([]*C.char, 0, len(set))
+		values := make([]*C.char, 0, len(set))
+		lens := make([]C.size_t, 0, len(set))
+		for key, value := range set {
+			k := C.CString(key)
+			v := (*C.char)(C.CBytes(value))
+			cStrings = append(cStrings, k, v)
+			keys = append(keys, k)
+			values = append(values, v)
+			lens = append(lens, C.size_t(len(value)))
+		}
+		C.rados_write_op_omap_set(wo.opContext, &keys[0], &values[0], &lens[0], C.size_t(len(keys)))
+	}
+	if len(remove) > 0 {
+		keys := make([]*C.char, len(remove))
+		for i, key := range remove {
+			keys[i] = C.CString(key)
+			cStrings = append(cStrings, keys[i])
+		}
+		C.rados_write_op_omap_rm_keys(wo.opContext, &keys[0], C.size_t(len(keys)))
+	}
+
+	return wo.Operate(o, nil)
+}
+
+// omap returns the omap entries of the object whose keys start with the prefix.
+func (o *Object) omap(prefix string) (map[string][]byte, error) {
+	oid := C.CString(o.name)
+	defer freeString(oid)
+	filter := C.CString(prefix)
+	defer freeString(filter)
+
+	entries := make(map[string][]byte)
+	after := ""
+	for {
+		more, last, err := o.omapPage(oid, filter, after, entries)
+		if err != nil {
+			return nil, err
+		}
+		if !more {
+			return entries, nil
+		}
+		after = last
+	}
+}
+
+// omapPage reads a page of omap entries after the given key into entries. This returns whether more entries are
+// available and the last key read.
+func (o *Object) omapPage(oid, filter *C.char, after string, entries map[string][]byte) (bool, string, error) {
+	ro, err := newReadOperation(o.ioContext)
+	if err != nil {
+		return false, "", err
+	}
+	defer ro.Release()
+
+	startAfter := C.CString(after)
+	defer freeString(startAfter)
+
+	var iter C.rados_omap_iter_t
+	var more C.uchar
+	var retVal C.int
+	C.rados_read_op_omap_get_vals2(ro.opContext, startAfter, filter, omapPageSize, &iter, &more, &retVal)
+	ret := C.rados_read_op_operate(ro.opContext, ro.ioContext, oid, 0)
+	if ret == 0 {
+		ret = retVal
+	}
+	if err := toRadosError(ret); err != nil {
+		err.Message = fmt.Sprintf("Unable to read the omap of object %s.", o.name)
+		return false, "", err
+	}
+	defer C.rados_omap_get_end(iter)
+
+	last := after
+	for {
+		var key, value *C.char
+		var valueLen C.size_t
+		if ret := C.rados_omap_get_next(iter, &key, &value, &valueLen); ret != 0 {
+			err := toRadosError(ret)
+			err.Message = fmt.Sprintf("Unable to read the omap of object %s.", o.name)
+			return false, "", err
+		}
+		if key == nil {
+			break
+		}
+		last = C.GoString(key)
+		entries[last] = C.GoBytes(unsafe.Pointer(value), C.int(valueLen))
+	}
+	return more != 0, last, nil
+}
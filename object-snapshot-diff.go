@@ -0,0 +1,231 @@
+package grados
+
+/*
+#cgo LDFLAGS: -lrados
+#include <rados/librados.h>
+*/
+import "C"
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"syscall"
+)
+
+// SnapshotDiff is the difference of an object between two snapshots.
+type SnapshotDiff struct {
+	Exists  bool     // False if the object does not exist at the target snapshot.
+	Size    uint64   // The size of the object at the target snapshot. Data past the size was truncated.
+	Extents []Extent // The extents that changed, ordered by offset and within Size.
+}
+
+// DiffSnapshots returns the extents of the object that changed between the snapshots from and to, using the overlap
+// information of the clones. Use NoSnapshot as to to compare with the head of the object. If the object did not exist
+// at from, its whole content at to is returned as changed.
+func (o *Object) DiffSnapshots(from, to SnapshotId) (*SnapshotDiff, error) {
+	if from > to {
+		err := toRadosError(-C.int(syscall.EINVAL))
+		err.Message = fmt.Sprintf("Snapshot %d is newer than snapshot %d.", from, to)
+		return nil, err
+	}
+	snapshots, err := o.ListSnapshots()
+	if err != nil {
+		return nil, err
+	}
+	return diffSnapSet(snapshots, from, to), nil
+}
+
+// diffSnapSet computes the difference between the snapshots from and to. The extents that changed between a clone and
+// the next newer one are the ones that are not in the overlap of the clone.
+func diffSnapSet(snapshots *ObjectSnapshots, from, to SnapshotId) *SnapshotDiff {
+	diff := &SnapshotDiff{Extents: make([]Extent, 0)}
+	toIndex := snapshots.cloneAt(to)
+	if toIndex < 0 {
+		return diff
+	}
+	diff.Exists = true
+	diff.Size = snapshots.Clones[toIndex].Size
+
+	fromIndex := snapshots.cloneAt(from)
+	if fromIndex < 0 {
+		if diff.Size > 0 {
+			diff.Extents = append(diff.Extents, Extent{Offset: 0, Length: diff.Size})
+		}
+		return diff
+	}
+
+	changed := make([]Extent, 0)
+	for i := fromIndex; i < toIndex; i++ {
+		clone, next := snapshots.Clones[i], snapshots.Clones[i+1]
+		size := clone.Size
+		if next.Size > size {
+			size = next.Size
+		}
+		changed = append(changed, subtractExtents(Extent{Offset: 0, Length: size}, clone.Overlap)...)
+	}
+	diff.Extents = clipExtents(mergeExtents(changed), diff.Size)
+	return diff
+}
+
+// cloneAt returns the index of the clone holding the content of the object at the snapshot, or -1 if the object did
+// not exist at the snapshot.
+func (s *ObjectSnapshots) cloneAt(snapshot SnapshotId) int {
+	for i, clone := range s.Clones {
+		if clone.IsHead() {
+			if snapshot == NoSnapshot || snapshot > s.Seq {
+				return i
+			}
+			return -1
+		}
+		if clone.Id < snapshot {
+			continue
+		}
+		for _, id := range clone.Snapshots {
+			if id == snapshot {
+				return i
+			}
+		}
+		return -1
+	}
+	return -1
+}
+
+// subtractExtents returns the parts of the extent not covered by the other extents.
+func subtractExtents(extent Extent, other []Extent) []Extent {
+	result := make([]Extent, 0)
+	pos := extent.Offset
+	end := extent.Offset + extent.Length
+	for _, o := range mergeExtents(other) {
+		oEnd := o.Offset + o.Length
+		if oEnd <= pos || o.Offset >= end {
+			continue
+		}
+		if o.Offset > pos {
+			result = append(result, Extent{Offset: pos, Length: o.Offset - pos})
+		}
+		pos = oEnd
+	}
+	if pos < end {
+		result = append(result, Extent{Offset: pos, Length: end - pos})
+	}
+	return result
+}
+
+// mergeExtents sorts the extents and merges the overlapping and adjacent ones.
+func mergeExtents(extents []Extent) []Extent {
+	sorted := make([]Extent, 0, len(extents))
+	for _, extent := range extents {
+		if extent.Length > 0 {
+			sorted = append(sorted, extent)
+		}
+	}
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Offset < sorted[j].Offset })
+
+	result := make([]Extent, 0, len(sorted))
+	for _, extent := range sorted {
+		if n := len(result); n > 0 && result[n-1].Offset+result[n-1].Length >= extent.Offset {
+			if end := extent.Offset + extent.Length; end > result[n-1].Offset+result[n-1].Length {
+				result[n-1].Length = end - result[n-1].Offset
+			}
+			continue
+		}
+		result = append(result, extent)
+	}
+	return result
+}
+
+// clipExtents drops the parts of the sorted extents past the size.
+func clipExtents(extents []Extent, size uint64) []Extent {
+	result := make([]Extent, 0, len(extents))
+	for _, extent := range extents {
+		if extent.Offset >= size {
+			break
+		}
+		if extent.Offset+extent.Length > size {
+			extent.Length = size - extent.Offset
+		}
+		result = append(result, extent)
+	}
+	return result
+}
+
+// DiffReader iterates over the changed extents of an object between two snapshots together with their data at the
+// target snapshot. Extents are read at most windowSize bytes at a time. Use OpenDiffReader from an object to create a
+// valid instance.
+type DiffReader struct {
+	object  *Object
+	view    *PoolView
+	window  uint64
+	diff    *SnapshotDiff
+	pending []Extent
+}
+
+// OpenDiffReader returns a DiffReader of the changes of the object between the snapshots from and to. The data is read
+// through a view of the pool at the target snapshot, so the reader must be closed when no longer used.
+func (o *Object) OpenDiffReader(from, to SnapshotId, windowSize uint64) (*DiffReader, error) {
+	if windowSize == 0 {
+		err := toRadosError(-1)
+		err.Message = "Window size must be greater than 0."
+		return nil, err
+	}
+	diff, err := o.DiffSnapshots(from, to)
+	if err != nil {
+		return nil, err
+	}
+	view, err := newPoolView(o.ioContext, o.alignment, to)
+	if err != nil {
+		return nil, err
+	}
+	return newDiffReader(view.ManageObject(o.name), view, diff, windowSize), nil
+}
+
+// newDiffReader creates a DiffReader of the diff. The extents are copied so splitting them doesn't alter the diff.
+func newDiffReader(object *Object, view *PoolView, diff *SnapshotDiff, windowSize uint64) *DiffReader {
+	return &DiffReader{
+		object:  object,
+		view:    view,
+		window:  windowSize,
+		diff:    diff,
+		pending: append([]Extent(nil), diff.Extents...),
+	}
+}
+
+// Diff returns the difference being read.
+func (r *DiffReader) Diff() *SnapshotDiff {
+	return r.diff
+}
+
+// Next returns the next changed extent with its data at the target snapshot. Extents larger than the window are
+// returned in several parts. This returns io.EOF when there are no more extents.
+func (r *DiffReader) Next() (*SparseExtent, error) {
+	extent, ok := r.next()
+	if !ok {
+		return nil, io.EOF
+	}
+	data, err := r.object.Read(extent.Length, extent.Offset)
+	if err != nil {
+		return nil, err
+	}
+	return &SparseExtent{Extent: extent, Data: data}, nil
+}
+
+// next returns the next extent to read, at most the window size long.
+func (r *DiffReader) next() (Extent, bool) {
+	if len(r.pending) == 0 {
+		return Extent{}, false
+	}
+	extent := r.pending[0]
+	if extent.Length > r.window {
+		r.pending[0] = Extent{Offset: extent.Offset + r.window, Length: extent.Length - r.window}
+		extent.Length = r.window
+	} else {
+		r.pending = r.pending[1:]
+	}
+	return extent, true
+}
+
+// Close releases the view of the pool used to read the data.
+func (r *DiffReader) Close() {
+	r.view.Close()
+}
@@ -0,0 +1,118 @@
+package grados
+
+/*
+#cgo LDFLAGS: -lrados
+#include <stdlib.h>
+#include "librados-cxx.h"
+*/
+import "C"
+
+import (
+	"fmt"
+	"syscall"
+	"unsafe"
+)
+
+// ObjectClone is a clone of an object preserving its content for one or more snapshots. The head of the object is
+// listed as a clone with the id NoSnapshot.
+type ObjectClone struct {
+	Id        SnapshotId   // The id of the clone. This is the id of the newest snapshot it belongs to.
+	Snapshots []SnapshotId // The snapshots the clone belongs to.
+	Size      uint64       // The size of the object in the clone.
+	Overlap   []Extent     // The extents the clone shares with the next newer clone or the head.
+}
+
+// IsHead returns true if the clone is the head of the object.
+func (c *ObjectClone) IsHead() bool {
+	return c.Id == NoSnapshot
+}
+
+// ObjectSnapshots lists the clones of an object.
+type ObjectSnapshots struct {
+	Seq    SnapshotId     // The snapshot sequence of the head of the object.
+	Clones []*ObjectClone // The clones from oldest to newest, followed by the head if it exists.
+}
+
+// ListSnapshots returns the clones of the object with their snapshots, sizes and overlap extents. librados only lists
+// clones through its C++ API, so this can't be added as a step of a ReadOperation.
+func (o *Object) ListSnapshots() (*ObjectSnapshots, error) {
+	oid := C.CString(o.name)
+	defer freeString(oid)
+
+	var snaps *C.uint64_t
+	var snapsLen C.size_t
+	ret := C.grados_list_snaps(o.ioContext, oid, &snaps, &snapsLen)
+	if err := toRadosError(ret); err != nil {
+		err.Message = fmt.Sprintf("Unable to list the snapshots of object %s.", o.name)
+		return nil, err
+	}
+	defer C.free(unsafe.Pointer(snaps))
+
+	n := int(snapsLen)
+	values := make([]uint64, n)
+	for i, value := range (*[1 << 28]C.uint64_t)(unsafe.Pointer(snaps))[:n:n] {
+		values[i] = uint64(value)
+	}
+	snapshots, ok := decodeSnapSet(values)
+	if !ok {
+		err := toRadosError(-C.int(syscall.EINVAL))
+		err.Message = fmt.Sprintf("Invalid snapshot list of object %s.", o.name)
+		return nil, err
+	}
+	return snapshots, nil
+}
+
+// decodeSnapSet decodes the flattened snapshot set returned by grados_list_snaps. This returns false if the values are
+// truncated.
+func decodeSnapSet(values []uint64) (*ObjectSnapshots, bool) {
+	next := func() (uint64, bool) {
+		if len(values) == 0 {
+			return 0, false
+		}
+		value := values[0]
+		values = values[1:]
+		return value, true
+	}
+
+	seq, ok := next()
+	if !ok {
+		return nil, false
+	}
+	count, ok := next()
+	if !ok || count > uint64(len(values)) {
+		return nil, false
+	}
+	snapshots := &ObjectSnapshots{
+		Seq:    SnapshotId(seq),
+		Clones: make([]*ObjectClone, 0, count),
+	}
+	for i := uint64(0); i < count; i++ {
+		clone := new(ObjectClone)
+		var id, snapCount, overlapCount uint64
+		if id, ok = next(); !ok {
+			return nil, false
+		}
+		clone.Id = SnapshotId(id)
+		if clone.Size, ok = next(); !ok {
+			return nil, false
+		}
+		if snapCount, ok = next(); !ok || snapCount > uint64(len(values)) {
+			return nil, false
+		}
+		clone.Snapshots = make([]SnapshotId, snapCount)
+		for j := range clone.Snapshots {
+			snap, _ := next()
+			clone.Snapshots[j] = SnapshotId(snap)
+		}
+		if overlapCount, ok = next(); !ok || 2*overlapCount > uint64(len(values)) {
+			return nil, false
+		}
+		clone.Overlap = make([]Extent, overlapCount)
+		for j := range clone.Overlap {
+			clone.Overlap[j].Offset, _ = next()
+			clone.Overlap[j].Length, _ = next()
+		}
+		snapshots.Clones = append(snapshots.Clones, clone)
+	}
+	return snapshots, true
+}
@@ -0,0 +1,161 @@
+package grados
+
+/*
+#cgo LDFLAGS: -lrados
+#include <stdlib.h>
+#include "librados-cxx.h"
+*/
+import "C"
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"unsafe"
+)
+
+// Extent is a range of bytes of an object.
+type Extent struct {
+	Offset uint64 // The start of the extent.
+	Length uint64 // The length of the extent.
+}
+
+// SparseExtent is an allocated extent of an object together with its data.
+type SparseExtent struct {
+	Extent
+	Data io.Reader // The data of the extent.
+}
+
+// SparseRead reads a length of the object starting at the given offset and returns only the allocated extents. Holes
+// are skipped so they are not transferred. Use a ReadTransaction with a sparse read step to combine it with other read
+// steps.
+func (o *Object) SparseRead(offset, length uint64) ([]*SparseExtent, error) {
+	return NewReadTransaction().SparseRead(offset, length).OperateSparse(o)
+}
+
+// OperateSparse validates the transaction and applies it to the object returning the allocated extents read by its
+// sparse read step. librados only supports sparse reads through its C++ API, so the steps are performed by the C++ shim
+// instead of a ReadOperation.
+func (tx *ReadTransaction) OperateSparse(object *Object, flags ...LibradosOperation) ([]*SparseExtent, error) {
+	if err := tx.Validate(); err != nil {
+		return nil, err
+	}
+	if !tx.sparse() {
+		return nil, invalidTransaction("Read transaction has no sparse read step.")
+	}
+	oid := C.CString(object.name)
+	defer freeString(oid)
+
+	// the steps hold C pointers so they are allocated by C
+	n := len(tx.Steps)
+	stepsAddr := C.calloc(C.size_t(n), C.size_t(unsafe.Sizeof(C.grados_read_step{})))
+	defer C.free(stepsAddr)
+	steps := (*[1 << 20]C.grados_read_step)(stepsAddr)[:n:n]
+	for i, step := range tx.Steps {
+		steps[i].flags = C.int(step.Flags)
+		switch step.Type {
+		case StepAssertExists:
+			steps[i].kind = C.GRADOS_STEP_ASSERT_EXISTS
+		case StepCompareAttribute:
+			steps[i].kind = C.GRADOS_STEP_CMPXATTR
+			steps[i].name = C.CString(step.Name)
+			defer freeString(steps[i].name)
+			steps[i].op = C.uint8_t(step.Operator)
+			steps[i].value = (*C.char)(C.CBytes(step.Data))
+			defer C.free(unsafe.Pointer(steps[i].value))
+			steps[i].value_len = C.size_t(len(step.Data))
+		case StepSparseRead:
+			steps[i].kind = C.GRADOS_STEP_SPARSE_READ
+			steps[i].offset = C.uint64_t(step.Offset)
+			steps[i].length = C.uint64_t(step.Length)
+		}
+	}
+
+	var f C.int = 0
+	for _, flag := range flags {
+		f |= C.int(flag)
+	}
+
+	var extents *C.uint64_t
+	var extentsLen C.size_t
+	var data *C.char
+	var dataLen C.size_t
+
+	ret := C.grados_sparse_read_op(object.ioContext, oid, &steps[0], C.size_t(n), f, &extents, &extentsLen, &data, &dataLen)
+	if err := toRadosError(ret); err != nil {
+		err.Message = fmt.Sprintf("Unable to sparse read object %s.", object.name)
+		return nil, err
+	}
+	defer C.free(unsafe.Pointer(extents))
+	defer C.free(unsafe.Pointer(data))
+
+	count := int(extentsLen)
+	pairs := (*[1 << 28]C.uint64_t)(unsafe.Pointer(extents))[: 2*count : 2*count]
+	buf := C.GoBytes(unsafe.Pointer(data), C.int(dataLen))
+
+	result := make([]*SparseExtent, count)
+	var pos uint64
+	for i := 0; i < count; i++ {
+		extent := Extent{
+			Offset: uint64(pairs[2*i]),
+			Length: uint64(pairs[2*i+1]),
+		}
+		end := pos + extent.Length
+		if end > uint64(len(buf)) {
+			end = uint64(len(buf))
+		}
+		result[i] = &SparseExtent{
+			Extent: extent,
+			Data:   bytes.NewReader(buf[pos:end]),
+		}
+		pos = end
+	}
+	return result, nil
+}
+
+// SparseReader iterates over the allocated extents of an object. The object is sparse read one window at a time so
+// large objects can be streamed without holding them in memory.
+type SparseReader struct {
+	object  *Object
+	window  uint64
+	offset  uint64
+	size    uint64
+	pending []*SparseExtent
+}
+
+// OpenSparseReader returns a SparseReader of the object that reads windowSize bytes of the object at a time.
+func (o *Object) OpenSparseReader(windowSize uint64) (*SparseReader, error) {
+	if windowSize == 0 {
+		err := toRadosError(-1)
+		err.Message = "Window size must be greater than 0."
+		return nil, err
+	}
+	status, err := o.Status()
+	if err != nil {
+		return nil, err
+	}
+	return &SparseReader{
+		object: o,
+		window: windowSize,
+		size:   status.size,
+	}, nil
+}
+
+// Next returns the next allocated extent of the object. Extents spanning several windows are returned in several parts.
+// This returns io.EOF when there are no more extents.
+func (r *SparseReader) Next() (*SparseExtent, error) {
+	for len(r.pending) == 0 {
+		if r.offset >= r.size {
+			return nil, io.EOF
+		}
+		extents, err := r.object.SparseRead(r.offset, r.window)
+		if err != nil {
+			return nil, err
+		}
+		r.pending = extents
+		r.offset += r.window
+	}
+	extent := r.pending[0]
+	r.pending = r.pending[1:]
+	return extent, nil
+}
@@ -0,0 +1,32 @@
+package grados
+
+/*
+#include <stdint.h>
+#include <stddef.h>
+*/
+import "C"
+
+import (
+	"unsafe"
+)
+
+// The librados watch callbacks. These are kept apart from object-watch.go since files exporting functions to C can only
+// have declarations in their preamble.
+
+//export watchNotifyCallback
+func watchNotifyCallback(arg unsafe.Pointer, notifyId C.uint64_t, handle C.uint64_t, notifierId C.uint64_t, data unsafe.Pointer, dataLen C.size_t) {
+	w := lookupWatch(uintptr(arg))
+	if w == nil {
+		return
+	}
+	w.notify(uint64(notifyId), uint64(notifierId), C.GoBytes(data, C.int(dataLen)))
+}
+
+//export watchErrorCallback
+func watchErrorCallback(arg unsafe.Pointer, handle C.uint64_t, err C.int) {
+	w := lookupWatch(uintptr(arg))
+	if w == nil {
+		return
+	}
+	w.fail(int(err))
+}
@@ -0,0 +1,166 @@
+package grados
+
+import (
+	"sync"
+	"time"
+)
+
+const (
+	watchMinBackoff    = 100 * time.Millisecond // The first wait before watching an object again after an error.
+	watchMaxBackoff    = 30 * time.Second       // The longest wait between attempts to watch an object again.
+	watchCheckInterval = 30 * time.Second       // How often the watch is checked for errors librados did not report.
+)
+
+// WatchEventType is the type of a WatchEvent.
+type WatchEventType int
+
+const (
+	WatchNotification WatchEventType = iota // A notification was received.
+	WatchReconnected                        // The watch was registered again after an error. Notifications may have been missed.
+	WatchDropped                            // Notifications were dropped because the events were not received in time.
+)
+
+// WatchEvent is an event of a ResilientWatch.
+type WatchEvent struct {
+	Type         WatchEventType
+	Notification *Notification // The notification. Only set for WatchNotification events.
+	Err          error         // The error that invalidated the previous watch. Only set for WatchReconnected events.
+}
+
+// ResilientWatch is a watch that is registered again when librados reports an error, such as after the session of the
+// client was reset. Use WatchResilient from an object to create a valid instance.
+type ResilientWatch struct {
+	object *Object
+	events chan *WatchEvent
+
+	mutex sync.RWMutex
+	watch *Watch
+
+	done      chan struct{}
+	closeOnce sync.Once
+	wg        sync.WaitGroup
+}
+
+// WatchResilient registers a watch on the object that is registered again after errors. The object must exist.
+func (o *Object) WatchResilient() (*ResilientWatch, error) {
+	watch, err := o.Watch()
+	if err != nil {
+		return nil, err
+	}
+	rw := &ResilientWatch{
+		object: o,
+		events: make(chan *WatchEvent, 64),
+		watch:  watch,
+		done:   make(chan struct{}),
+	}
+	rw.wg.Add(1)
+	go rw.run()
+	return rw, nil
+}
+
+// Events returns the channel of notifications and reconnections of the watch.
+func (rw *ResilientWatch) Events() <-chan *WatchEvent {
+	return rw.events
+}
+
+// Check returns how long ago the watch was last confirmed by the OSD. This can be used as a health metric. An error is
+// returned while the watch is being registered again.
+func (rw *ResilientWatch) Check() (time.Duration, error) {
+	rw.mutex.RLock()
+	watch := rw.watch
+	rw.mutex.RUnlock()
+	if watch == nil {
+		err := toRadosError(-1)
+		err.Message = "Watch is being registered again."
+		return 0, err
+	}
+	return watch.Check()
+}
+
+// Close unregisters the watch.
+func (rw *ResilientWatch) Close() error {
+	var err error
+	rw.closeOnce.Do(func() {
+		close(rw.done)
+		rw.wg.Wait()
+		if rw.watch != nil {
+			err = rw.watch.Close()
+		}
+	})
+	return err
+}
+
+// run forwards the notifications of the current watch and replaces the watch when it fails.
+func (rw *ResilientWatch) run() {
+	defer rw.wg.Done()
+	ticker := time.NewTicker(watchCheckInterval)
+	defer ticker.Stop()
+	for {
+		var failure error
+		select {
+		case <-rw.done:
+			return
+		case notification := <-rw.watch.Notifications():
+			if !rw.send(&WatchEvent{Type: WatchNotification, Notification: notification}) {
+				return
+			}
+			continue
+		case <-rw.watch.Dropped():
+			if !rw.send(&WatchEvent{Type: WatchDropped}) {
+				return
+			}
+			continue
+		case failure = <-rw.watch.Errors():
+		case <-ticker.C:
+			if _, failure = rw.watch.Check(); failure == nil {
+				continue
+			}
+		}
+
+		rw.watch.Close()
+		rw.setWatch(nil)
+		watch := rw.rewatch()
+		if watch == nil {
+			return
+		}
+		rw.setWatch(watch)
+		if !rw.send(&WatchEvent{Type: WatchReconnected, Err: failure}) {
+			return
+		}
+	}
+}
+
+// rewatch watches the object again with an exponential backoff. This returns nil if the watch was closed before the
+// object could be watched.
+func (rw *ResilientWatch) rewatch() *Watch {
+	backoff := watchMinBackoff
+	for {
+		select {
+		case <-rw.done:
+			return nil
+		case <-time.After(backoff):
+		}
+		if watch, err := rw.object.Watch(); err == nil {
+			return watch
+		}
+		if backoff *= 2; backoff > watchMaxBackoff {
+			backoff = watchMaxBackoff
+		}
+	}
+}
+
+func (rw *ResilientWatch) setWatch(watch *Watch) {
+	rw.mutex.Lock()
+	defer rw.mutex.Unlock()
+	rw.watch = watch
+}
+
+// send passes the event to the events channel. This returns false if the watch was closed.
+func (rw *ResilientWatch) send(event *WatchEvent) bool {
+	select {
+	case rw.events <- event:
+		return true
+	case <-rw.done:
+		return false
+	}
+}
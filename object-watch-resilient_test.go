@@ -0,0 +1,63 @@
+package grados
+
+import (
+	"bytes"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestResilientWatch(t *testing.T) {
+	cluster := connect(t)
+	if cluster == nil {
+		return
+	}
+	defer cluster.Shutdown()
+
+	pool, err := cluster.ManagePool("data")
+	handleError(t, err)
+	if pool == nil {
+		return
+	}
+	defer pool.Close()
+
+	object := pool.ManageObject("resilient_watch")
+	handleError(t, object.WriteFull(bytes.NewBufferString("")))
+	defer object.Remove()
+
+	watch, err := object.WatchResilient()
+	handleError(t, err)
+	if watch == nil {
+		return
+	}
+	defer watch.Close()
+
+	_, err = watch.Check()
+	handleError(t, err)
+
+	// simulate a session reset reported by librados
+	watch.mutex.RLock()
+	watch.watch.fail(-int(syscall.ENOTCONN))
+	watch.mutex.RUnlock()
+
+	select {
+	case event := <-watch.Events():
+		if event.Type != WatchReconnected {
+			t.Errorf("event should be a reconnection, is %d", event.Type)
+		}
+	case <-time.After(5 * time.Second):
+		t.Error("watch should have been registered again")
+	}
+
+	_, err = object.Notify(bytes.NewBufferString("hello"), 5*time.Second)
+	handleError(t, err)
+
+	select {
+	case event := <-watch.Events():
+		if event.Type != WatchNotification || string(event.Notification.Data) != "hello" {
+			t.Errorf("event should be the hello notification, is %+v", event)
+		}
+	case <-time.After(5 * time.Second):
+		t.Error("notification should have been received")
+	}
+}
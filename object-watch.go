@@ -1,18 +1,263 @@
 package grados
 
-// /*
-// #cgo LDFLAGS: -lrados
-// #include <rados/librados.h>
-// */
-// import "C"
+/*
+#cgo LDFLAGS: -lrados
+#include <stdint.h>
+#include <rados/librados.h>
 
-// func (o *Object) Watch(version uint64) {
-// 	oid := C.CString(o.Name)
-// 	defer freeString(oid)
-// 	ret := C.rados_watch(o.ioContext, iod, C.uint64_t(version), uint64_t *handle, rados_watchcb_t watchcb, void *arg)
+extern void watchNotifyCallback(void *arg, uint64_t notifyId, uint64_t handle, uint64_t notifierId, void *data, size_t dataLen);
+extern void watchErrorCallback(void *arg, uint64_t handle, int err);
 
-// }
+// watch registers a watch passing the registry id of the Watch to the callbacks.
+static int watch(rados_ioctx_t io, const char *oid, uint64_t *handle, uintptr_t id) {
+	return rados_watch2(io, oid, handle, watchNotifyCallback, watchErrorCallback, (void *)id);
+}
+*/
+import "C"
 
-// func objectChangeCallback(opCode C.uint8_t, )
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"sync"
+	"syscall"
+	"time"
+	"unsafe"
+)
 
-// // void (*rados_watchcb_t)(uint8_t opcode, uint64_t ver, void *arg);
+// Notification is a notify message received by a watch.
+type Notification struct {
+	NotifyId   uint64 // The id of the notify.
+	NotifierId uint64 // The instance id of the client that sent the notify.
+	Data       []byte // The payload of the notify.
+}
+
+// Watch receives the notify messages sent to an object. Notifications are acknowledged as soon as they are received.
+// Notifications are received on a librados thread shared by all the watches of the cluster handle, so they are dropped
+// instead of waiting when the notifications channel is full. Use Watch from an object to create a valid instance.
+type Watch struct {
+	object        *Object
+	id            uintptr
+	handle        C.uint64_t
+	notifications chan *Notification
+	errors        chan error
+	dropped       chan struct{}
+	done          chan struct{}
+	closeOnce     sync.Once
+}
+
+// watches keeps track of the registered watches so the librados callbacks can find them. librados only passes the id
+// of the watch back to the callbacks since Go pointers can't be held by C code.
+var watches = struct {
+	sync.RWMutex
+	next uintptr
+	m    map[uintptr]*Watch
+}{m: make(map[uintptr]*Watch)}
+
+// Watch registers a watch on the object. The object must exist.
+func (o *Object) Watch() (*Watch, error) {
+	oid := C.CString(o.name)
+	defer freeString(oid)
+
+	w := &Watch{
+		object:        o,
+		notifications: make(chan *Notification, 64),
+		errors:        make(chan error, 8),
+		dropped:       make(chan struct{}, 1),
+		done:          make(chan struct{}),
+	}
+
+	watches.Lock()
+	watches.next++
+	w.id = watches.next
+	watches.m[w.id] = w
+	watches.Unlock()
+
+	ret := C.watch(o.ioContext, oid, &w.handle, C.uintptr_t(w.id))
+	if err := toRadosError(ret); err != nil {
+		err.Message = fmt.Sprintf("Unable to watch object %s.", o.name)
+		unregisterWatch(w.id)
+		return nil, err
+	}
+	return w, nil
+}
+
+// Notifications returns the channel of notifications received by the watch.
+func (w *Watch) Notifications() <-chan *Notification {
+	return w.notifications
+}
+
+// Errors returns the channel of errors reported by librados for the watch. The watch is no longer valid when an error
+// is received and should be closed.
+func (w *Watch) Errors() <-chan error {
+	return w.errors
+}
+
+// Dropped returns a channel that receives a value when notifications were dropped because the notifications channel
+// was full. Several drops before the value is received are reported once. The watch stays valid.
+func (w *Watch) Dropped() <-chan struct{} {
+	return w.dropped
+}
+
+// Check returns how long ago the watch was last confirmed by the OSD. An error is returned if the watch is no longer
+// valid.
+func (w *Watch) Check() (time.Duration, error) {
+	ret := C.rados_watch_check(w.object.ioContext, w.handle)
+	if err := toRadosError(ret); err != nil {
+		err.Message = fmt.Sprintf("Watch on object %s is not valid.", w.object.name)
+		return 0, err
+	}
+	return time.Duration(ret) * time.Millisecond, nil
+}
+
+// Close unregisters the watch. No notifications are received after this.
+func (w *Watch) Close() error {
+	var err error
+	w.closeOnce.Do(func() {
+		close(w.done)
+		ret := C.rados_unwatch2(w.object.ioContext, w.handle)
+		unregisterWatch(w.id)
+		if e := toRadosError(ret); e != nil {
+			e.Message = fmt.Sprintf("Unable to unwatch object %s.", w.object.name)
+			err = e
+		}
+	})
+	return err
+}
+
+// notify acknowledges the notification and passes it to the notifications channel. This runs on the librados callback
+// thread, so the notification is dropped and reported on the dropped channel if the notifications channel is full.
+func (w *Watch) notify(notifyId, notifierId uint64, data []byte) {
+	oid := C.CString(w.object.name)
+	defer freeString(oid)
+	C.rados_notify_ack(w.object.ioContext, oid, C.uint64_t(notifyId), w.handle, nil, 0)
+
+	select {
+	case w.notifications <- &Notification{NotifyId: notifyId, NotifierId: notifierId, Data: data}:
+	case <-w.done:
+	default:
+		select {
+		case w.dropped <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// fail passes the error to the errors channel. The error is dropped if nobody is listening.
+func (w *Watch) fail(code int) {
+	err := &RadosError{
+		Code:    code,
+		Message: fmt.Sprintf("Watch on object %s failed.", w.object.name),
+	}
+	select {
+	case w.errors <- err:
+	default:
+	}
+}
+
+func lookupWatch(id uintptr) *Watch {
+	watches.RLock()
+	defer watches.RUnlock()
+	return watches.m[id]
+}
+
+func unregisterWatch(id uintptr) {
+	watches.Lock()
+	defer watches.Unlock()
+	delete(watches.m, id)
+}
+
+// NotifyAck is the acknowledgement of a notify by a watcher.
+type NotifyAck struct {
+	ClientId uint64 // The instance id of the watching client.
+	Cookie   uint64 // The watch handle of the watching client.
+	Reply    []byte // The reply of the watcher.
+}
+
+// NotifyTimeout is a watcher that did not acknowledge a notify in time.
+type NotifyTimeout struct {
+	ClientId uint64 // The instance id of the watching client.
+	Cookie   uint64 // The watch handle of the watching client.
+}
+
+// NotifyResult contains the watchers that acknowledged a notify and the ones that timed out.
+type NotifyResult struct {
+	Acks     []*NotifyAck
+	Timeouts []*NotifyTimeout
+}
+
+// Notify sends a notify message to all the watchers of the object and waits until they acknowledge it or the timeout
+// expires. If some watchers timed out, the result is returned together with the timeout error.
+func (o *Object) Notify(data io.Reader, timeout time.Duration) (*NotifyResult, error) {
+	oid := C.CString(o.name)
+	defer freeString(oid)
+
+	bufAddr, bufLen := readerToBuf(data)
+
+	var reply *C.char
+	var replyLen C.size_t
+	ret := C.rados_notify2(o.ioContext, oid, bufAddr, C.int(bufLen), C.uint64_t(timeout/time.Millisecond), &reply, &replyLen)
+	if reply != nil {
+		defer C.rados_buffer_free(reply)
+	}
+
+	var result *NotifyResult
+	if reply != nil && replyLen > 0 {
+		result = decodeNotifyReply(C.GoBytes(unsafe.Pointer(reply), C.int(replyLen)))
+	}
+	if int(ret) == -int(syscall.ETIMEDOUT) {
+		err := toRadosError(ret)
+		err.Message = fmt.Sprintf("Some watchers of object %s did not acknowledge the notify.", o.name)
+		return result, err
+	}
+	if err := toRadosError(ret); err != nil {
+		err.Message = fmt.Sprintf("Unable to notify object %s.", o.name)
+		return nil, err
+	}
+	if result == nil {
+		result = new(NotifyResult)
+	}
+	return result, nil
+}
+
+// decodeNotifyReply decodes the reply buffer of a notify. The buffer contains the number of acks followed by the
+// client id, cookie and reply of each ack, then the number of timeouts followed by the client id and cookie of each
+// timeout. All integers are little endian.
+func decodeNotifyReply(buf []byte) *NotifyResult {
+	result := &NotifyResult{
+		Acks:     make([]*NotifyAck, 0),
+		Timeouts: make([]*NotifyTimeout, 0),
+	}
+	r := bytes.NewReader(buf)
+	var count uint32
+	if binary.Read(r, binary.LittleEndian, &count) != nil {
+		return result
+	}
+	for i := uint32(0); i < count; i++ {
+		ack := new(NotifyAck)
+		var replyLen uint32
+		if binary.Read(r, binary.LittleEndian, &ack.ClientId) != nil ||
+			binary.Read(r, binary.LittleEndian, &ack.Cookie) != nil ||
+			binary.Read(r, binary.LittleEndian, &replyLen) != nil {
+			return result
+		}
+		ack.Reply = make([]byte, replyLen)
+		if _, err := io.ReadFull(r, ack.Reply); err != nil {
+			return result
+		}
+		result.Acks = append(result.Acks, ack)
+	}
+	if binary.Read(r, binary.LittleEndian, &count) != nil {
+		return result
+	}
+	for i := uint32(0); i < count; i++ {
+		timeout := new(NotifyTimeout)
+		if binary.Read(r, binary.LittleEndian, &timeout.ClientId) != nil ||
+			binary.Read(r, binary.LittleEndian, &timeout.Cookie) != nil {
+			return result
+		}
+		result.Timeouts = append(result.Timeouts, timeout)
+	}
+	return result
+}
@@ -3,10 +3,32 @@ package grados
 /*
 #cgo LDFLAGS: -lrados
 #include <rados/librados.h>
+
+// rados_stat2 (timespec mtime) can't be detected from the librados version, so it is declared weak and looked up at run
+// time. Clients without it fall back to rados_stat and lose the sub-second part of the modification time.
+#pragma weak rados_stat2
+extern int rados_stat2(rados_ioctx_t io, const char *o, uint64_t *psize, struct timespec *pmtime);
+
+static int has_stat2() {
+	return rados_stat2 != NULL;
+}
+
+static int stat_object(rados_ioctx_t io, const char *oid, uint64_t *size, struct timespec *mtime) {
+	time_t t;
+	int ret;
+	if (has_stat2()) {
+		return rados_stat2(io, oid, size, mtime);
+	}
+	ret = rados_stat(io, oid, size, &t);
+	mtime->tv_sec = t;
+	mtime->tv_nsec = 0;
+	return ret;
+}
 */
 import "C"
 
 import (
+	"bytes"
 	"fmt"
 	"io"
 	"syscall"
@@ -48,6 +70,7 @@ type Object struct {
 	ioContext   C.rados_ioctx_t
 	name        string
 	watchHandle C.uint64_t
+	alignment   AlignmentPolicy
 }
 
 // ManageObject manages an object. This can read/write/update/delete objects.
@@ -55,6 +78,7 @@ func (pool *Pool) ManageObject(name string) *Object {
 	return &Object{
 		ioContext: pool.context,
 		name:      name,
+		alignment: pool.alignment,
 	}
 }
 
@@ -91,6 +115,10 @@ func (o *Object) WriteFull(data io.Reader) error {
 
 // Append appends new data to the object
 func (o *Object) Append(data io.Reader) error {
+	data, err := o.align(data)
+	if err != nil {
+		return err
+	}
 	oid := C.CString(o.name)
 	defer freeString(oid)
 	bufAddr, length := readerToBuf(data)
@@ -102,6 +130,76 @@ func (o *Object) Append(data io.Reader) error {
 	return nil
 }
 
+// WriteWithModifiedTime writes the data at a specific offset to the object and sets the object's modification time to
+// modifiedTime.
+func (o *Object) WriteWithModifiedTime(data io.Reader, offset uint64, modifiedTime time.Time) error {
+	return o.operateWithModifiedTime(modifiedTime, func(wo *WriteOperation) {
+		wo.Write(data, offset)
+	})
+}
+
+// WriteFullWithModifiedTime writes the entire data to the object replacing old data and sets the object's
+// modification time to modifiedTime.
+func (o *Object) WriteFullWithModifiedTime(data io.Reader, modifiedTime time.Time) error {
+	return o.operateWithModifiedTime(modifiedTime, func(wo *WriteOperation) {
+		wo.WriteFull(data)
+	})
+}
+
+// AppendWithModifiedTime appends new data to the object and sets the object's modification time to modifiedTime.
+func (o *Object) AppendWithModifiedTime(data io.Reader, modifiedTime time.Time) error {
+	data, err := o.align(data)
+	if err != nil {
+		return err
+	}
+	return o.operateWithModifiedTime(modifiedTime, func(wo *WriteOperation) {
+		wo.Append(data)
+	})
+}
+
+// align applies the alignment policy to the data of an append if the pool requires alignment.
+func (o *Object) align(data io.Reader) (io.Reader, error) {
+	buf := readAll(data)
+	aligned, alignment, ok := alignAppend(o.ioContext, o.alignment, buf)
+	if !ok {
+		err := toRadosError(-C.int(syscall.EINVAL))
+		err.Message = fmt.Sprintf("Unable to append %d bytes to object %s. The pool requires appends aligned to %d bytes.", len(buf), o.name, alignment)
+		return nil, err
+	}
+	return bytes.NewReader(aligned), nil
+}
+
+// alignAppend applies the alignment policy to the data of an append if the pool of the io context requires alignment.
+// This returns false along with the required alignment if the data is misaligned and the policy rejects it.
+func alignAppend(ioContext C.rados_ioctx_t, policy AlignmentPolicy, buf []byte) ([]byte, uint64, bool) {
+	if C.rados_ioctx_pool_requires_alignment(ioContext) == 0 {
+		return buf, 0, true
+	}
+	alignment := uint64(C.rados_ioctx_pool_required_alignment(ioContext))
+	if alignment == 0 {
+		return buf, alignment, true
+	}
+	misaligned := uint64(len(buf)) % alignment
+	if misaligned == 0 {
+		return buf, alignment, true
+	}
+	if policy == PadMisaligned {
+		return append(buf, make([]byte, alignment-misaligned)...), alignment, true
+	}
+	return nil, alignment, false
+}
+
+// operateWithModifiedTime performs a single step write operation on the object using the given modification time.
+func (o *Object) operateWithModifiedTime(modifiedTime time.Time, step func(wo *WriteOperation)) error {
+	wo, err := newWriteOperation(o.ioContext, o.alignment)
+	if err != nil {
+		return err
+	}
+	defer wo.Release()
+	step(wo)
+	return wo.Operate(o, &modifiedTime)
+}
+
 // Read reads a specified length of data from the object starting at the given offset.
 func (o *Object) Read(length, offset uint64) (io.Reader, error) {
 	oid := C.CString(o.name)
@@ -170,22 +268,37 @@ type ObjectStatus struct {
 	modifiedTime time.Time
 }
 
+// Size returns the size of the object in bytes.
+func (status *ObjectStatus) Size() uint64 {
+	return status.size
+}
+
+// ModifiedTime returns the modification time of the object, with nanosecond precision if librados supports it.
+func (status *ObjectStatus) ModifiedTime() time.Time {
+	return status.modifiedTime
+}
+
+// preciseStatus returns true if librados reports modification times with nanosecond precision.
+func preciseStatus() bool {
+	return C.has_stat2() != 0
+}
+
 // Status returns the status of an object.
 func (o *Object) Status() (*ObjectStatus, error) {
 	oid := C.CString(o.name)
 	defer freeString(oid)
 
 	var objectSize C.uint64_t
-	var modifiedTime C.time_t
+	var modifiedTime C.struct_timespec
 
-	ret := C.rados_stat(o.ioContext, oid, &objectSize, &modifiedTime)
+	ret := C.stat_object(o.ioContext, oid, &objectSize, &modifiedTime)
 	if err := toRadosError(ret); err != nil {
 		err.Message = fmt.Sprintf("Unable to get status for object %s.", o.name)
 		return nil, err
 	}
 	return &ObjectStatus{
 		size:         uint64(objectSize),
-		modifiedTime: time.Unix(int64(modifiedTime), 0),
+		modifiedTime: time.Unix(int64(modifiedTime.tv_sec), int64(modifiedTime.tv_nsec)),
 	}, nil
 }
 
@@ -199,9 +312,9 @@ func (o *Object) SetAllocationHint(expectedObjectSize, expectedWriteSize uint64)
 	C.rados_set_alloc_hint(o.ioContext, oid, es, ews)
 }
 
-// LockExclusive exclusively locks an object. At the moment, lock duration cannot be specified so this locks the object
-// indefinitely.
-func (o *Object) LockExclusive(name, cookie, description string, flags ...LibradosLock) error {
+// LockExclusive exclusively locks an object. The lock expires after the given duration unless it is renewed. A duration
+// of 0 locks the object indefinitely. Pass the Renew flag to renew a lock already held by the current client.
+func (o *Object) LockExclusive(name, cookie, description string, duration time.Duration, flags ...LibradosLock) error {
 	oid := C.CString(o.name)
 	defer freeString(oid)
 
@@ -218,23 +331,13 @@ func (o *Object) LockExclusive(name, cookie, description string, flags ...Librad
 	for _, flag := range flags {
 		f |= int(flag)
 	}
-	ret := C.rados_lock_exclusive(o.ioContext, oid, n, c, d, nil, C.uint8_t(f))
-	switch int(ret) {
-	case -int(syscall.EBUSY):
-		err := toRadosError(ret)
-		err.Message = fmt.Sprintf("%s is already locked by another client", o.name)
-		return err
-	case -int(syscall.EEXIST):
-		err := toRadosError(ret)
-		err.Message = fmt.Sprintf("%s is already locked by current client", o.name)
-		return err
-	}
-	return nil
+	ret := C.rados_lock_exclusive(o.ioContext, oid, n, c, d, lockDuration(duration), C.uint8_t(f))
+	return o.lockError(ret, name)
 }
 
-// LockShared share locks an object. At the moment, lock duration cannot be specified so this locks the object
-// indefinitely.
-func (o *Object) LockShared(name, cookie, tag, description string, flags ...LibradosLock) error {
+// LockShared share locks an object. The lock expires after the given duration unless it is renewed. A duration of 0
+// locks the object indefinitely. Pass the Renew flag to renew a lock already held by the current client.
+func (o *Object) LockShared(name, cookie, tag, description string, duration time.Duration, flags ...LibradosLock) error {
 	oid := C.CString(o.name)
 	defer freeString(oid)
 
@@ -255,7 +358,24 @@ func (o *Object) LockShared(name, cookie, tag, description string, flags ...Libr
 		f |= int(flag)
 	}
 
-	ret := C.rados_lock_shared(o.ioContext, oid, n, c, t, d, nil, C.uint8_t(f))
+	ret := C.rados_lock_shared(o.ioContext, oid, n, c, t, d, lockDuration(duration), C.uint8_t(f))
+	return o.lockError(ret, name)
+}
+
+// RenewLockExclusive extends the duration of an exclusive lock held by the current client. The object is locked if the
+// lock is not held anymore.
+func (o *Object) RenewLockExclusive(name, cookie, description string, duration time.Duration) error {
+	return o.LockExclusive(name, cookie, description, duration, Renew)
+}
+
+// RenewLockShared extends the duration of a shared lock held by the current client. The object is locked if the lock is
+// not held anymore.
+func (o *Object) RenewLockShared(name, cookie, tag, description string, duration time.Duration) error {
+	return o.LockShared(name, cookie, tag, description, duration, Renew)
+}
+
+// lockError converts the result of a lock call to an error.
+func (o *Object) lockError(ret C.int, name string) error {
 	switch int(ret) {
 	case -int(syscall.EBUSY):
 		err := toRadosError(ret)
@@ -266,9 +386,28 @@ func (o *Object) LockShared(name, cookie, tag, description string, flags ...Libr
 		err.Message = fmt.Sprintf("%s is already locked by current client", o.name)
 		return err
 	}
+	if err := toRadosError(ret); err != nil {
+		err.Message = fmt.Sprintf("Unable to lock %s with lock %s", o.name, name)
+		return err
+	}
 	return nil
 }
 
+// lockDuration converts a lock duration to a timeval. A duration of 0 means the lock does not expire. Durations are
+// rounded up to the microsecond since a zero timeval would also mean the lock does not expire.
+func lockDuration(duration time.Duration) *C.struct_timeval {
+	if duration <= 0 {
+		return nil
+	}
+	if remainder := duration % time.Microsecond; remainder != 0 {
+		duration += time.Microsecond - remainder
+	}
+	return &C.struct_timeval{
+		tv_sec:  C.time_t(duration / time.Second),
+		tv_usec: C.suseconds_t((duration % time.Second) / time.Microsecond),
+	}
+}
+
 // Unlock unlocks a locked object. This returns an error if the lock is not owned by the current client.
 func (o *Object) Unlock(name, cookie string) error {
 	oid := C.CString(o.name)
@@ -317,15 +456,44 @@ func (o *Object) BreakLock(name, client, cookie string) error {
 	return nil
 }
 
+// LockType is the type of an object lock.
+type LockType int
+
+const (
+	ExclusiveLock LockType = 1 // The lock is held by a single client.
+	SharedLock    LockType = 2 // The lock can be held by several clients.
+)
+
+// String returns the name of the lock type.
+func (lockType LockType) String() string {
+	switch lockType {
+	case ExclusiveLock:
+		return "exclusive"
+	case SharedLock:
+		return "shared"
+	}
+	return fmt.Sprintf("unknown(%d)", int(lockType))
+}
+
 // Locker represents a client that has locked an object.
 type Locker struct {
-	Client  string // The client.
-	Cookies string // The cookies.
-	Address string // The address.
+	Client     string    // The client.
+	Cookie     string    // The lock cookie of the client.
+	Address    string    // The address of the client.
+	Expiration time.Time // When the lock expires. This is the zero time if the lock does not expire.
 }
 
-// ListLockers show all the clients that has locks on the object. This also returns the lock tag if any.
-func (o *Object) ListLockers(name string) ([]*Locker, string, error) {
+// LockInfo describes a lock of an object and its holders.
+type LockInfo struct {
+	Name    string    // The lock name.
+	Type    LockType  // Whether the lock is exclusive or shared.
+	Tag     string    // The lock tag. Only set for shared locks.
+	Lockers []*Locker // The clients holding the lock.
+}
+
+// ListLockers returns the type, tag and holders of the named lock of the object. Lock expirations are retrieved from
+// the lock object class and are left to the zero time if the class can't be queried.
+func (o *Object) ListLockers(name string) (*LockInfo, error) {
 	oid := C.CString(o.name)
 	defer freeString(oid)
 
@@ -341,6 +509,7 @@ func (o *Object) ListLockers(name string) ([]*Locker, string, error) {
 	var addrsLen C.size_t
 
 	for {
+		tagLen, clientsLen, cookiesLen, addrsLen = C.size_t(bufLen), C.size_t(bufLen), C.size_t(bufLen), C.size_t(bufLen)
 		cTag := bufferAddress(bufLen)
 		cClients := bufferAddress(bufLen)
 		cCookies := bufferAddress(bufLen)
@@ -352,26 +521,111 @@ func (o *Object) ListLockers(name string) ([]*Locker, string, error) {
 			continue
 		}
 		if err := toRadosError(C.int(ret)); err != nil {
-			err.Message = fmt.Sprintf("Unable to get lockers for object %s.", o.name)
-			return nil, "", err
+			err.Message = fmt.Sprintf("Unable to get lockers of lock %s for object %s.", name, o.name)
+			return nil, err
 		}
 
-		tag := C.GoStringN(cTag, C.int(tagLen))
+		info := &LockInfo{
+			Name:    name,
+			Type:    SharedLock,
+			Tag:     C.GoString(cTag),
+			Lockers: make([]*Locker, 0, int(ret)),
+		}
+		if exclusive != 0 {
+			info.Type = ExclusiveLock
+		}
 
 		clients := bufToStringSlice(cClients, C.int(clientsLen))
 		cookies := bufToStringSlice(cCookies, C.int(cookiesLen))
 		addrs := bufToStringSlice(cAddrs, C.int(addrsLen))
-
-		lockers := make([]*Locker, ret)
-		for i := 0; i < int(ret); i++ {
-			lockers[i] = &Locker{
+		for i := 0; i < int(ret) && i < len(clients) && i < len(cookies) && i < len(addrs); i++ {
+			info.Lockers = append(info.Lockers, &Locker{
 				Client:  clients[i],
-				Cookies: cookies[i],
+				Cookie:  cookies[i],
 				Address: addrs[i],
+			})
+		}
+
+		if expirations, err := o.lockExpirations(name); err == nil {
+			for _, locker := range info.Lockers {
+				locker.Expiration = expirations[locker.Client+"/"+locker.Cookie]
 			}
 		}
+		return info, nil
+	}
+}
+
+// lockExpirations returns the expiration of each holder of the lock keyed by client and cookie. This calls the
+// get_info method of the lock object class.
+func (o *Object) lockExpirations(name string) (map[string]time.Time, error) {
+	in := new(cephEncoder)
+	in.versioned(1, 1, func(e *cephEncoder) {
+		e.string(name)
+	})
+	out, err := o.execute("lock", "get_info", in.Bytes())
+	if err != nil {
+		return nil, err
+	}
+
+	expirations := make(map[string]time.Time)
+	d := newCephDecoder(out)
+	d.versioned(func(d *cephDecoder) {
+		count := d.u32()
+		for i := uint32(0); i < count && d.err == nil; i++ {
+			var client, cookie string
+			d.versioned(func(d *cephDecoder) {
+				client = entityName(d.u8(), int64(d.u64()))
+				cookie = d.string()
+			})
+			var expiration time.Time
+			d.versioned(func(d *cephDecoder) {
+				sec, nsec := d.u32(), d.u32()
+				if sec != 0 || nsec != 0 {
+					expiration = time.Unix(int64(sec), int64(nsec))
+				}
+			})
+			expirations[client+"/"+cookie] = expiration
+		}
+	})
+	if d.err != nil {
+		return nil, d.err
+	}
+	return expirations, nil
+}
 
-		return lockers, tag, nil
+// ListLocks returns the names of all the locks of the object. This calls the list_locks method of the lock object
+// class.
+func (o *Object) ListLocks() ([]string, error) {
+	out, err := o.execute("lock", "list_locks", nil)
+	if err != nil {
+		return nil, err
 	}
+	locks := make([]string, 0)
+	d := newCephDecoder(out)
+	d.versioned(func(d *cephDecoder) {
+		count := d.u32()
+		for i := uint32(0); i < count && d.err == nil; i++ {
+			locks = append(locks, d.string())
+		}
+	})
+	if d.err != nil {
+		return nil, d.err
+	}
+	return locks, nil
+}
 
+// entityName formats a ceph entity name the way librados does (eg. client.4123).
+func entityName(entityType uint8, num int64) string {
+	names := map[uint8]string{
+		0x01: "mon",
+		0x02: "mds",
+		0x04: "osd",
+		0x08: "client",
+		0x10: "mgr",
+	}
+	name, ok := names[entityType]
+	if !ok {
+		name = "unknown"
+	}
+	return fmt.Sprintf("%s.%d", name, num)
 }
@@ -1,4 +1,424 @@
 package grados
 
+import (
+	"bytes"
+	"hash/crc32"
+	"io"
+	"testing"
+	"time"
+)
+
 // TODO
 // Most of the tests are in snapshots. should move some here
+
+func TestWriteWithModifiedTime(t *testing.T) {
+	cluster := connect(t)
+	if cluster == nil {
+		return
+	}
+	defer cluster.Shutdown()
+
+	pool, err := cluster.ManagePool("data")
+	handleError(t, err)
+	if pool == nil {
+		return
+	}
+	defer pool.Close()
+
+	mtime := time.Date(2015, time.March, 1, 10, 30, 0, 123456789, time.UTC)
+	object := pool.ManageObject("mtime_object")
+	err = object.WriteFullWithModifiedTime(bytes.NewBufferString("data"), mtime)
+	handleError(t, err)
+	defer object.Remove()
+
+	expected := mtime
+	if !preciseStatus() {
+		expected = mtime.Truncate(time.Second)
+	}
+	status, err := object.Status()
+	handleError(t, err)
+	if status != nil && !status.ModifiedTime().Equal(expected) {
+		t.Errorf("modified time should be %v, modified time is %v", expected, status.ModifiedTime())
+	}
+	if status != nil && status.ModifiedTime().Nanosecond() != expected.Nanosecond() {
+		t.Errorf("nanoseconds should be %d, nanoseconds are %d", expected.Nanosecond(), status.ModifiedTime().Nanosecond())
+	}
+}
+
+func TestChecksum(t *testing.T) {
+	cluster := connect(t)
+	if cluster == nil {
+		return
+	}
+	defer cluster.Shutdown()
+
+	pool, err := cluster.ManagePool("data")
+	handleError(t, err)
+	if pool == nil {
+		return
+	}
+	defer pool.Close()
+
+	object := pool.ManageObject("checksum_object")
+	err = object.WriteFull(bytes.NewBufferString("0123456789abcdef"))
+	handleError(t, err)
+	defer object.Remove()
+
+	checksums, err := object.Checksum(ChecksumCRC32C, 0, 16, 4)
+	handleError(t, err)
+	if len(checksums) != 4 {
+		t.Errorf("should have 4 checksums, has %d", len(checksums))
+	}
+	// ceph seeds crc32c with 0xffffffff and does not invert the result, while hash/crc32 inverts both
+	table := crc32.MakeTable(crc32.Castagnoli)
+	for i, checksum := range checksums {
+		chunk := []byte("0123456789abcdef")[4*i : 4*i+4]
+		if expected := uint64(^crc32.Checksum(chunk, table)); checksum != expected {
+			t.Errorf("checksum %d should be %x, is %x", i, expected, checksum)
+		}
+	}
+
+	whole, err := object.Checksum(ChecksumXXHash64, 0, 0, 0)
+	handleError(t, err)
+	if len(whole) != 1 {
+		t.Errorf("should have 1 checksum, has %d", len(whole))
+	}
+}
+
+func TestSparseRead(t *testing.T) {
+	cluster := connect(t)
+	if cluster == nil {
+		return
+	}
+	defer cluster.Shutdown()
+
+	pool, err := cluster.ManagePool("data")
+	handleError(t, err)
+	if pool == nil {
+		return
+	}
+	defer pool.Close()
+
+	object := pool.ManageObject("sparse_object")
+	err = object.Write(bytes.NewBufferString("data"), 1<<20)
+	handleError(t, err)
+	defer object.Remove()
+
+	// the 1 MiB hole before the data should be skipped
+	extents, err := object.SparseRead(0, 2<<20)
+	handleError(t, err)
+	if len(extents) != 1 {
+		t.Fatalf("should have 1 extent, has %d", len(extents))
+	}
+	if extents[0].Extent != (Extent{Offset: 1 << 20, Length: 4}) {
+		t.Errorf("extent should be %d+4, is %d+%d", 1<<20, extents[0].Offset, extents[0].Length)
+	}
+	if data := string(readAll(extents[0].Data)); data != "data" {
+		t.Errorf("extent data should be data, is %q", data)
+	}
+
+	extents, err = NewReadTransaction().AssertExists().SparseRead(0, 2<<20).SetFlags(OperationFailOk).OperateSparse(object)
+	handleError(t, err)
+	if len(extents) != 1 || extents[0].Extent != (Extent{Offset: 1 << 20, Length: 4}) {
+		t.Fatalf("transaction should read the %d+4 extent only, reads %d extents", 1<<20, len(extents))
+	}
+	if data := string(readAll(extents[0].Data)); data != "data" {
+		t.Errorf("transaction extent data should be data, is %q", data)
+	}
+
+	reader, err := object.OpenSparseReader(1 << 19)
+	handleError(t, err)
+	if reader == nil {
+		return
+	}
+	streamed := make([]*SparseExtent, 0)
+	for {
+		extent, err := reader.Next()
+		if err == io.EOF {
+			break
+		}
+		handleError(t, err)
+		if err != nil {
+			return
+		}
+		streamed = append(streamed, extent)
+	}
+	if len(streamed) != 1 || streamed[0].Extent != (Extent{Offset: 1 << 20, Length: 4}) {
+		t.Fatalf("should stream the %d+4 extent only, streams %d extents", 1<<20, len(streamed))
+	}
+	if data := string(readAll(streamed[0].Data)); data != "data" {
+		t.Errorf("streamed extent data should be data, is %q", data)
+	}
+}
+
+func TestLockDuration(t *testing.T) {
+	if lockDuration(0) != nil {
+		t.Error("duration of 0 should not expire")
+	}
+	d := lockDuration(time.Nanosecond)
+	if d == nil || d.tv_sec != 0 || d.tv_usec != 1 {
+		t.Errorf("duration of 1ns should be rounded up to 1us, is %+v", d)
+	}
+	d = lockDuration(1500 * time.Millisecond)
+	if d == nil || d.tv_sec != 1 || d.tv_usec != 500000 {
+		t.Errorf("duration of 1.5s should be 1s and 500000us, is %+v", d)
+	}
+}
+
+func TestTimedLock(t *testing.T) {
+	cluster := connect(t)
+	if cluster == nil {
+		return
+	}
+	defer cluster.Shutdown()
+
+	pool, err := cluster.ManagePool("data")
+	handleError(t, err)
+	if pool == nil {
+		return
+	}
+	defer pool.Close()
+
+	object := pool.ManageObject("lock_object")
+	err = object.WriteFull(bytes.NewBufferString("data"))
+	handleError(t, err)
+	defer object.Remove()
+
+	err = object.LockExclusive("lock", "cookie", "timed lock", 2*time.Second)
+	handleError(t, err)
+	err = object.RenewLockExclusive("lock", "cookie", "timed lock", 2*time.Second)
+	handleError(t, err)
+	if err := object.LockExclusive("lock", "other", "timed lock", 2*time.Second); err == nil {
+		t.Error("lock should be busy")
+	}
+
+	time.Sleep(3 * time.Second)
+	err = object.LockExclusive("lock", "other", "timed lock", 0)
+	handleError(t, err)
+	err = object.Unlock("lock", "other")
+	handleError(t, err)
+}
+
+func TestListLocks(t *testing.T) {
+	cluster := connect(t)
+	if cluster == nil {
+		return
+	}
+	defer cluster.Shutdown()
+
+	pool, err := cluster.ManagePool("data")
+	handleError(t, err)
+	if pool == nil {
+		return
+	}
+	defer pool.Close()
+
+	object := pool.ManageObject("list_locks_object")
+	err = object.WriteFull(bytes.NewBufferString("data"))
+	handleError(t, err)
+	defer object.Remove()
+
+	handleError(t, object.LockExclusive("exclusive", "cookie", "", time.Minute))
+	handleError(t, object.LockShared("shared", "cookie", "tag", "", 0))
+
+	locks, err := object.ListLocks()
+	handleError(t, err)
+	if len(locks) != 2 {
+		t.Errorf("should have 2 locks, has %v", locks)
+	}
+
+	info, err := object.ListLockers("exclusive")
+	handleError(t, err)
+	if info != nil {
+		if info.Type != ExclusiveLock || len(info.Lockers) != 1 {
+			t.Errorf("should be exclusively locked once, info is %+v", info)
+		} else if info.Lockers[0].Cookie != "cookie" || info.Lockers[0].Expiration.IsZero() {
+			t.Errorf("unexpected locker %+v", info.Lockers[0])
+		}
+	}
+
+	info, err = object.ListLockers("shared")
+	handleError(t, err)
+	if info != nil && (info.Type != SharedLock || info.Tag != "tag") {
+		t.Errorf("should be shared locked with tag, info is %+v", info)
+	}
+}
+
+func TestDecodeSnapSet(t *testing.T) {
+	values := []uint64{
+		7, 2,
+		4, 100, 2, 3, 4, 1, 0, 50,
+		uint64(NoSnapshot), 80, 0, 0,
+	}
+	snapshots, ok := decodeSnapSet(values)
+	if !ok {
+		t.Fatal("snap set should decode")
+	}
+	if snapshots.Seq != 7 || len(snapshots.Clones) != 2 {
+		t.Fatalf("should have seq 7 and 2 clones, has %+v", snapshots)
+	}
+	clone := snapshots.Clones[0]
+	if clone.Id != 4 || clone.Size != 100 || len(clone.Snapshots) != 2 || clone.Snapshots[1] != 4 {
+		t.Errorf("unexpected clone %+v", clone)
+	}
+	if len(clone.Overlap) != 1 || clone.Overlap[0] != (Extent{Offset: 0, Length: 50}) {
+		t.Errorf("unexpected overlap %+v", clone.Overlap)
+	}
+	if !snapshots.Clones[1].IsHead() || snapshots.Clones[1].Size != 80 {
+		t.Errorf("second clone should be the head, is %+v", snapshots.Clones[1])
+	}
+
+	if _, ok := decodeSnapSet(values[:len(values)-1]); ok {
+		t.Error("truncated snap set should not decode")
+	}
+}
+
+func TestListSnapshots(t *testing.T) {
+	cluster := connect(t)
+	if cluster == nil {
+		return
+	}
+	defer cluster.Shutdown()
+
+	pool, err := cluster.ManagePool("data")
+	handleError(t, err)
+	if pool == nil {
+		return
+	}
+	defer pool.Close()
+	defer pool.ManageObject("list_snapshots_registry").Remove()
+
+	object := pool.ManageObject("list_snapshots")
+	handleError(t, object.WriteFull(bytes.NewBufferString("before")))
+	defer object.Remove()
+
+	registry := pool.SnapshotRegistry("list_snapshots_registry")
+	snapshot, err := registry.Create("snap")
+	handleError(t, err)
+	if snapshot == nil {
+		return
+	}
+	defer registry.Remove("snap")
+	handleError(t, object.WriteFull(bytes.NewBufferString("after, longer")))
+
+	snapshots, err := object.ListSnapshots()
+	handleError(t, err)
+	if snapshots == nil {
+		return
+	}
+	if len(snapshots.Clones) != 2 {
+		t.Fatalf("should have a clone and the head, has %d clones", len(snapshots.Clones))
+	}
+	if clone := snapshots.Clones[0]; clone.Size != 6 || len(clone.Snapshots) != 1 || clone.Snapshots[0] != snapshot.Id {
+		t.Errorf("clone should be 6 bytes for snapshot %d, is %+v", snapshot.Id, clone)
+	}
+	if !snapshots.Clones[1].IsHead() {
+		t.Error("last clone should be the head")
+	}
+}
+
+func TestDiffSnapSet(t *testing.T) {
+	// clone 3 for snapshots 2 and 3, clone 5 for snapshot 5, then the head
+	snapshots := &ObjectSnapshots{
+		Seq: 5,
+		Clones: []*ObjectClone{
+			{Id: 3, Snapshots: []SnapshotId{2, 3}, Size: 100, Overlap: []Extent{{0, 10}, {20, 80}}},
+			{Id: 5, Snapshots: []SnapshotId{5}, Size: 100, Overlap: []Extent{{0, 50}}},
+			{Id: NoSnapshot, Size: 60},
+		},
+	}
+
+	diff := diffSnapSet(snapshots, 2, 3)
+	if !diff.Exists || diff.Size != 100 || len(diff.Extents) != 0 {
+		t.Errorf("snapshots of the same clone should not differ, diff is %+v", diff)
+	}
+
+	diff = diffSnapSet(snapshots, 2, 5)
+	if len(diff.Extents) != 1 || diff.Extents[0] != (Extent{10, 10}) {
+		t.Errorf("diff from 2 to 5 should be 10+10, is %+v", diff.Extents)
+	}
+
+	diff = diffSnapSet(snapshots, 3, NoSnapshot)
+	if diff.Size != 60 || len(diff.Extents) != 2 || diff.Extents[0] != (Extent{10, 10}) || diff.Extents[1] != (Extent{50, 10}) {
+		t.Errorf("diff from 3 to head should be 10+10 and 50+10 in 60 bytes, is %+v", diff)
+	}
+
+	diff = diffSnapSet(snapshots, 1, 5)
+	if len(diff.Extents) != 1 || diff.Extents[0] != (Extent{0, 100}) {
+		t.Errorf("object created after snapshot 1 should be entirely changed, is %+v", diff.Extents)
+	}
+
+	diff = diffSnapSet(snapshots, 4, 4)
+	if diff.Exists {
+		t.Error("object should not exist at snapshot 4")
+	}
+}
+
+func TestDiffReaderWindow(t *testing.T) {
+	diff := &SnapshotDiff{Exists: true, Size: 100, Extents: []Extent{{0, 10}, {50, 4}}}
+	reader := newDiffReader(nil, nil, diff, 4)
+
+	expected := []Extent{{0, 4}, {4, 4}, {8, 2}, {50, 4}}
+	for _, e := range expected {
+		extent, ok := reader.next()
+		if !ok || extent != e {
+			t.Errorf("next extent should be %+v, is %+v", e, extent)
+		}
+	}
+	if _, ok := reader.next(); ok {
+		t.Error("reader should have no more extents")
+	}
+	if len(reader.Diff().Extents) != 2 || reader.Diff().Extents[0] != (Extent{0, 10}) {
+		t.Errorf("diff should not be changed by reading, is %+v", reader.Diff().Extents)
+	}
+}
+
+func TestDiffReader(t *testing.T) {
+	cluster := connect(t)
+	if cluster == nil {
+		return
+	}
+	defer cluster.Shutdown()
+
+	pool, err := cluster.ManagePool("data")
+	handleError(t, err)
+	if pool == nil {
+		return
+	}
+	defer pool.Close()
+	defer pool.ManageObject("diff_reader_registry").Remove()
+
+	object := pool.ManageObject("diff_reader")
+	handleError(t, object.WriteFull(bytes.NewBufferString("0123456789")))
+	defer object.Remove()
+
+	registry := pool.SnapshotRegistry("diff_reader_registry")
+	first, err := registry.Create("first")
+	handleError(t, err)
+	defer registry.Remove("first")
+	handleError(t, object.Write(bytes.NewBufferString("ab"), 4))
+	second, err := registry.Create("second")
+	handleError(t, err)
+	defer registry.Remove("second")
+	if first == nil || second == nil {
+		return
+	}
+
+	reader, err := object.OpenDiffReader(first.Id, second.Id, 1024)
+	handleError(t, err)
+	if reader == nil {
+		return
+	}
+	defer reader.Close()
+	changed := ""
+	for {
+		extent, err := reader.Next()
+		if err != nil {
+			break
+		}
+		changed += string(readAll(extent.Data))
+	}
+	if !bytes.Contains([]byte(changed), []byte("ab")) {
+		t.Errorf("changed data should contain ab, is %q", changed)
+	}
+}
@@ -0,0 +1,58 @@
+package grados
+
+/*
+#cgo LDFLAGS: -lrados
+#include <rados/librados.h>
+*/
+import "C"
+
+import (
+	"fmt"
+	"syscall"
+)
+
+// EnableApplication tags the pool with the application that uses it, eg. rbd, rgw, cephfs or a custom name. Enabling an
+// application that is already enabled does nothing.
+func (pool *Pool) EnableApplication(app string) error {
+	a := C.CString(app)
+	defer freeString(a)
+	ret := C.rados_application_enable(pool.context, a, 0)
+	if err := toRadosError(ret); err != nil {
+		err.Message = fmt.Sprintf("Unable to enable application %s on the pool.", app)
+		return err
+	}
+	return nil
+}
+
+// ListApplications returns the applications enabled on the pool.
+func (pool *Pool) ListApplications() ([]string, error) {
+	bufLen := C.size_t(256)
+	for {
+		bufAddr := bufferAddress(int(bufLen))
+		ret := C.rados_application_list(pool.context, bufAddr, &bufLen)
+		if int(ret) == -int(syscall.ERANGE) {
+			continue
+		}
+		if err := toRadosError(ret); err != nil {
+			err.Message = "Unable to list the applications of the pool."
+			return nil, err
+		}
+		return bufToStringSlice(bufAddr, C.int(bufLen)), nil
+	}
+}
+
+// SetApplicationMetadata sets a metadata key of an application enabled on the pool.
+func (pool *Pool) SetApplicationMetadata(app, key, value string) error {
+	a := C.CString(app)
+	defer freeString(a)
+	k := C.CString(key)
+	defer freeString(k)
+	v := C.CString(value)
+	defer freeString(v)
+	ret := C.rados_application_metadata_set(pool.context, a, k, v)
+	if err := toRadosError(ret); err != nil {
+		err.Message = fmt.Sprintf("Unable to set metadata %s of application %s.", key, app)
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,114 @@
+package grados
+
+import (
+	"strconv"
+)
+
+// CompressionMode is the compression mode of a pool.
+type CompressionMode string
+
+const (
+	CompressionNone       CompressionMode = "none"       // Never compress data.
+	CompressionPassive    CompressionMode = "passive"    // Compress data if the client hints it is compressible.
+	CompressionAggressive CompressionMode = "aggressive" // Compress data unless the client hints it is incompressible.
+	CompressionForce      CompressionMode = "force"      // Always compress data.
+)
+
+// PgAutoscaleMode is the placement group autoscale mode of a pool.
+type PgAutoscaleMode string
+
+const (
+	PgAutoscaleOff  PgAutoscaleMode = "off"  // Placement groups are not autoscaled.
+	PgAutoscaleWarn PgAutoscaleMode = "warn" // A health warning is raised if the placement group count should change.
+	PgAutoscaleOn   PgAutoscaleMode = "on"   // Placement groups are autoscaled.
+)
+
+// PoolProperties are the configurable properties of a pool. Properties that are not set on the pool have their zero
+// value.
+type PoolProperties struct {
+	Size                     uint            `json:"size"`                       // The number of replicas.
+	MinSize                  uint            `json:"min_size"`                   // The minimum number of replicas to serve IO.
+	PgNum                    uint            `json:"pg_num"`                     // The number of placement groups.
+	PgpNum                   uint            `json:"pgp_num"`                    // The number of placement groups used for placement.
+	CrushRule                string          `json:"crush_rule"`                 // The name of the crush rule.
+	CompressionMode          CompressionMode `json:"compression_mode"`           // The compression mode.
+	CompressionAlgorithm     string          `json:"compression_algorithm"`      // The compression algorithm, eg. snappy.
+	CompressionRequiredRatio float64         `json:"compression_required_ratio"` // The ratio compressed data must reach to be kept compressed.
+	TargetSizeRatio          float64         `json:"target_size_ratio"`          // The expected share of the cluster capacity, for the autoscaler.
+	PgAutoscaleMode          PgAutoscaleMode `json:"pg_autoscale_mode"`          // The placement group autoscale mode.
+}
+
+// Properties returns the properties of the pool.
+func (pool *Pool) Properties() (*PoolProperties, error) {
+	properties := new(PoolProperties)
+	args := map[string]interface{}{
+		"prefix": "osd pool get",
+		"pool":   pool.Name(),
+		"var":    "all",
+	}
+	if err := pool.Cluster().monCommand(args, properties); err != nil {
+		return nil, err
+	}
+	return properties, nil
+}
+
+// SetSize sets the number of replicas of the pool.
+func (pool *Pool) SetSize(size uint) error {
+	return pool.setProperty("size", strconv.FormatUint(uint64(size), 10))
+}
+
+// SetMinSize sets the minimum number of replicas required to serve IO.
+func (pool *Pool) SetMinSize(minSize uint) error {
+	return pool.setProperty("min_size", strconv.FormatUint(uint64(minSize), 10))
+}
+
+// SetPgNum sets the number of placement groups of the pool.
+func (pool *Pool) SetPgNum(pgNum uint) error {
+	return pool.setProperty("pg_num", strconv.FormatUint(uint64(pgNum), 10))
+}
+
+// SetPgpNum sets the number of placement groups used for placement.
+func (pool *Pool) SetPgpNum(pgpNum uint) error {
+	return pool.setProperty("pgp_num", strconv.FormatUint(uint64(pgpNum), 10))
+}
+
+// SetCrushRule sets the crush rule of the pool by name.
+func (pool *Pool) SetCrushRule(rule string) error {
+	return pool.setProperty("crush_rule", rule)
+}
+
+// SetCompressionMode sets the compression mode of the pool.
+func (pool *Pool) SetCompressionMode(mode CompressionMode) error {
+	return pool.setProperty("compression_mode", string(mode))
+}
+
+// SetCompressionAlgorithm sets the compression algorithm of the pool, eg. snappy, zlib, zstd or lz4.
+func (pool *Pool) SetCompressionAlgorithm(algorithm string) error {
+	return pool.setProperty("compression_algorithm", algorithm)
+}
+
+// SetCompressionRequiredRatio sets the ratio compressed data must reach to be kept compressed.
+func (pool *Pool) SetCompressionRequiredRatio(ratio float64) error {
+	return pool.setProperty("compression_required_ratio", strconv.FormatFloat(ratio, 'f', -1, 64))
+}
+
+// SetTargetSizeRatio sets the expected share of the cluster capacity used by the pool.
+func (pool *Pool) SetTargetSizeRatio(ratio float64) error {
+	return pool.setProperty("target_size_ratio", strconv.FormatFloat(ratio, 'f', -1, 64))
+}
+
+// SetPgAutoscaleMode sets the placement group autoscale mode of the pool.
+func (pool *Pool) SetPgAutoscaleMode(mode PgAutoscaleMode) error {
+	return pool.setProperty("pg_autoscale_mode", string(mode))
+}
+
+// setProperty sets a pool property with the osd pool set mon command.
+func (pool *Pool) setProperty(name, value string) error {
+	args := map[string]interface{}{
+		"prefix": "osd pool set",
+		"pool":   pool.Name(),
+		"var":    name,
+		"val":    value,
+	}
+	return pool.Cluster().monCommand(args, nil)
+}
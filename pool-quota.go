@@ -0,0 +1,50 @@
+package grados
+
+import (
+	"strconv"
+)
+
+// PoolQuota is the quota of a pool. A limit of 0 means unlimited.
+type PoolQuota struct {
+	MaxBytes   uint64 `json:"quota_max_bytes"`   // The maximum number of bytes stored in the pool.
+	MaxObjects uint64 `json:"quota_max_objects"` // The maximum number of objects stored in the pool.
+}
+
+// SetQuota limits the bytes and objects stored in the pool. Writes fail once a limit is reached. A limit of 0 removes
+// the limit. The byte limit is set before the object limit, so the byte limit is kept if setting the object limit fails.
+func (pool *Pool) SetQuota(maxBytes, maxObjects uint64) error {
+	name := pool.Name()
+	cluster := pool.Cluster()
+	quotas := []struct {
+		field string
+		value uint64
+	}{
+		{"max_bytes", maxBytes},
+		{"max_objects", maxObjects},
+	}
+	for _, quota := range quotas {
+		args := map[string]interface{}{
+			"prefix": "osd pool set-quota",
+			"pool":   name,
+			"field":  quota.field,
+			"val":    strconv.FormatUint(quota.value, 10),
+		}
+		if err := cluster.monCommand(args, nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Quota returns the quota of the pool.
+func (pool *Pool) Quota() (*PoolQuota, error) {
+	quota := new(PoolQuota)
+	args := map[string]interface{}{
+		"prefix": "osd pool get-quota",
+		"pool":   pool.Name(),
+	}
+	if err := pool.Cluster().monCommand(args, quota); err != nil {
+		return nil, err
+	}
+	return quota, nil
+}
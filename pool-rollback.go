@@ -0,0 +1,112 @@
+package grados
+
+import (
+	"context"
+	"sync"
+	"syscall"
+)
+
+// RollbackOptions configures RollbackPoolToSnapshot.
+type RollbackOptions struct {
+	Concurrency int                    // The number of objects rolled back at a time. Defaults to 1.
+	Filter      func(name string) bool // Only roll back the objects for which this returns true. All objects if nil.
+
+	// Progress is called after each object is rolled back with the result and the number of objects done so far. Calls
+	// are serialized. This can be nil.
+	Progress func(result *RollbackResult, done int)
+}
+
+// RollbackResult is the result of rolling back an object.
+type RollbackResult struct {
+	Object string // The object name.
+	Err    error  // The error if the rollback failed.
+}
+
+// RollbackReport contains the results of RollbackPoolToSnapshot.
+type RollbackReport struct {
+	Results []*RollbackResult
+}
+
+// Failed returns the results of the objects that could not be rolled back.
+func (report *RollbackReport) Failed() []*RollbackResult {
+	failed := make([]*RollbackResult, 0)
+	for _, result := range report.Results {
+		if result.Err != nil {
+			failed = append(failed, result)
+		}
+	}
+	return failed
+}
+
+// RollbackPoolToSnapshot rolls back every object of the pool, or the ones accepted by the filter, to the pool snapshot
+// in parallel. Failures of single objects are reported in the results and do not stop the rollback. The report is
+// returned even if listing the objects fails or the context is done.
+func (pool *Pool) RollbackPoolToSnapshot(ctx context.Context, snapshotName string, options *RollbackOptions) (*RollbackReport, error) {
+	if options == nil {
+		options = new(RollbackOptions)
+	}
+	concurrency := options.Concurrency
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	report := &RollbackReport{
+		Results: make([]*RollbackResult, 0),
+	}
+	if _, err := pool.LookupPoolSnapshot(snapshotName); err != nil {
+		return report, err
+	}
+
+	var mutex sync.Mutex
+	var wg sync.WaitGroup
+	jobs := make(chan string)
+	for w := 0; w < concurrency; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for name := range jobs {
+				result := &RollbackResult{
+					Object: name,
+					Err:    pool.RollbackToPoolSnapshot(name, snapshotName),
+				}
+				mutex.Lock()
+				report.Results = append(report.Results, result)
+				if options.Progress != nil {
+					options.Progress(result, len(report.Results))
+				}
+				mutex.Unlock()
+			}
+		}()
+	}
+
+	err := pool.dispatchObjects(ctx, options.Filter, jobs)
+	close(jobs)
+	wg.Wait()
+	return report, err
+}
+
+// dispatchObjects sends the names of the objects of the pool accepted by the filter to the jobs channel until all the
+// objects are listed or the context is done.
+func (pool *Pool) dispatchObjects(ctx context.Context, filter func(name string) bool, jobs chan<- string) error {
+	objects, err := pool.OpenObjectList()
+	if err != nil {
+		return err
+	}
+	defer objects.Close()
+	for {
+		object, _, err := objects.Next()
+		if err != nil {
+			if radosErr, ok := err.(*RadosError); ok && radosErr.Code == -int(syscall.ENOENT) {
+				return nil
+			}
+			return err
+		}
+		if filter != nil && !filter(object.name) {
+			continue
+		}
+		select {
+		case jobs <- object.name:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
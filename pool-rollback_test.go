@@ -0,0 +1,67 @@
+package grados
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestRollbackPoolToSnapshot(t *testing.T) {
+	cluster := connect(t)
+	if cluster == nil {
+		return
+	}
+	defer cluster.Shutdown()
+
+	if err := cluster.CreatePool("rollbackTest"); err != nil {
+		t.Error("Unable to create pool")
+		return
+	}
+	defer cluster.DeletePool("rollbackTest")
+
+	pool, err := cluster.ManagePool("rollbackTest")
+	handleError(t, err)
+	if pool == nil {
+		return
+	}
+	defer pool.Close()
+
+	names := []string{"keep1", "keep2", "skip"}
+	for _, name := range names {
+		handleError(t, pool.ManageObject(name).WriteFull(bytes.NewBufferString("before")))
+	}
+	handleError(t, pool.CreatePoolSnapshot("rollback"))
+	for _, name := range names {
+		handleError(t, pool.ManageObject(name).WriteFull(bytes.NewBufferString("after")))
+	}
+
+	snapshots, err := pool.ListPoolSnapshots()
+	handleError(t, err)
+	if len(snapshots) != 1 || snapshots[0].Name != "rollback" || snapshots[0].Timestamp.IsZero() {
+		t.Errorf("should list the rollback snapshot, lists %+v", snapshots)
+	}
+
+	progress := 0
+	report, err := pool.RollbackPoolToSnapshot(context.Background(), "rollback", &RollbackOptions{
+		Concurrency: 2,
+		Filter:      func(name string) bool { return strings.HasPrefix(name, "keep") },
+		Progress:    func(result *RollbackResult, done int) { progress = done },
+	})
+	handleError(t, err)
+	if len(report.Results) != 2 || len(report.Failed()) != 0 || progress != 2 {
+		t.Errorf("should roll back 2 objects, results %d, failed %d, progress %d", len(report.Results), len(report.Failed()), progress)
+	}
+
+	for _, name := range names {
+		data, err := pool.ManageObject(name).Read(6, 0)
+		handleError(t, err)
+		expected := "before"
+		if name == "skip" {
+			expected = "after"
+		}
+		if value := string(readAll(data)); value != expected {
+			t.Errorf("%s should read %s, reads %s", name, expected, value)
+		}
+	}
+}
@@ -0,0 +1,138 @@
+package grados
+
+/*
+#cgo LDFLAGS: -lrados
+#include <rados/librados.h>
+*/
+import "C"
+
+import (
+	"fmt"
+	"syscall"
+	"time"
+)
+
+// PoolView is a view of a pool whose objects are read at a snapshot. It uses its own io context so reading from the
+// view does not change the read snapshot of the pool it was created from, and it can be used concurrently with the
+// pool. The view copies the namespace and the alignment policy of the pool. The locator key can't be read back from
+// librados so it is not copied, use SetLocatorKey on the view if needed. Only read and lookup functions of the pool are
+// available. Objects returned by ManageObject have the write functions of any object, but librados fails all writes
+// with EROFS since the io context reads at a snapshot. Use AtSnapshot or AtPoolSnapshot from a pool to create a valid
+// instance.
+type PoolView struct {
+	pool     *Pool
+	snapshot SnapshotId
+}
+
+// AtSnapshot returns a view of the pool reading objects at the snapshot. The view must be closed when no longer used.
+func (pool *Pool) AtSnapshot(snapId SnapshotId) (*PoolView, error) {
+	return newPoolView(pool.context, pool.alignment, snapId)
+}
+
+// AtPoolSnapshot returns a view of the pool reading objects at the pool snapshot with the given name. The view must be
+// closed when no longer used.
+func (pool *Pool) AtPoolSnapshot(snapshotName string) (*PoolView, error) {
+	snapId, err := pool.LookupPoolSnapshot(snapshotName)
+	if err != nil {
+		return nil, err
+	}
+	return pool.AtSnapshot(snapId)
+}
+
+// Snapshot returns the snapshot the objects of the view are read at.
+func (view *PoolView) Snapshot() SnapshotId {
+	return view.snapshot
+}
+
+// Close releases the io context of the view. The pool the view was created from is not closed.
+func (view *PoolView) Close() {
+	view.pool.Close()
+}
+
+// Id returns the id of the pool.
+func (view *PoolView) Id() int64 {
+	return view.pool.Id()
+}
+
+// Name returns the name of the pool.
+func (view *PoolView) Name() string {
+	return view.pool.Name()
+}
+
+// Status retrieves the PoolStatus.
+func (view *PoolView) Status() (*PoolStatus, error) {
+	return view.pool.Status()
+}
+
+// SetLocatorKey sets the key used to locate the objects of the view.
+func (view *PoolView) SetLocatorKey(key string) {
+	view.pool.SetLocatorKey(key)
+}
+
+// ManageObject returns an object of the view. Objects read their content at the snapshot of the view.
+func (view *PoolView) ManageObject(name string) *Object {
+	return view.pool.ManageObject(name)
+}
+
+// OpenObjectList returns an ObjectList of the objects of the view.
+func (view *PoolView) OpenObjectList() (*ObjectList, error) {
+	return view.pool.OpenObjectList()
+}
+
+// CreateReadOperation returns a read operation reading objects at the snapshot of the view.
+func (view *PoolView) CreateReadOperation() (*ReadOperation, error) {
+	return view.pool.CreateReadOperation()
+}
+
+// ListPoolSnapshots returns the snapshots of the pool.
+func (view *PoolView) ListPoolSnapshots() ([]*PoolSnapshot, error) {
+	return view.pool.ListPoolSnapshots()
+}
+
+// LookupPoolSnapshot returns the id of the pool snapshot with the given name.
+func (view *PoolView) LookupPoolSnapshot(snapshotName string) (SnapshotId, error) {
+	return view.pool.LookupPoolSnapshot(snapshotName)
+}
+
+// ReverseLookupSnapshot returns the name of the pool snapshot with the given id.
+func (view *PoolView) ReverseLookupSnapshot(snapId SnapshotId) (string, error) {
+	return view.pool.ReverseLookupSnapshot(snapId)
+}
+
+// SnapshotTimestamp returns the creation time of the pool snapshot with the given id.
+func (view *PoolView) SnapshotTimestamp(snapId SnapshotId) (time.Time, error) {
+	return view.pool.SnapshotTimestamp(snapId)
+}
+
+// newPoolView duplicates the io context, including its namespace and alignment policy, and sets the read snapshot of
+// the duplicate.
+func newPoolView(ioContext C.rados_ioctx_t, alignment AlignmentPolicy, snapId SnapshotId) (*PoolView, error) {
+	var dup C.rados_ioctx_t
+	ret := C.rados_ioctx_create2(C.rados_ioctx_get_cluster(ioContext), C.rados_ioctx_get_id(ioContext), &dup)
+	if err := toRadosError(ret); err != nil {
+		err.Message = fmt.Sprintf("Unable to create IO Context for snapshot %d.", snapId)
+		return nil, err
+	}
+
+	bufLen := 64
+	for {
+		bufAddr := bufferAddress(bufLen)
+		ret := C.rados_ioctx_get_namespace(ioContext, bufAddr, C.unsigned(bufLen))
+		if int(ret) == -int(syscall.ERANGE) {
+			bufLen *= 2
+			continue
+		}
+		if ret > 0 {
+			namespace := C.CString(C.GoStringN(bufAddr, ret))
+			C.rados_ioctx_set_namespace(dup, namespace)
+			freeString(namespace)
+		}
+		break
+	}
+
+	C.rados_ioctx_snap_set_read(dup, C.rados_snap_t(snapId))
+	return &PoolView{
+		pool:     &Pool{context: dup, alignment: alignment},
+		snapshot: snapId,
+	}, nil
+}
@@ -0,0 +1,65 @@
+package grados
+
+import (
+	"bytes"
+	"syscall"
+	"testing"
+)
+
+func TestAtSnapshot(t *testing.T) {
+	cluster := connect(t)
+	if cluster == nil {
+		return
+	}
+	defer cluster.Shutdown()
+
+	pool, err := cluster.ManagePool("data")
+	handleError(t, err)
+	if pool == nil {
+		return
+	}
+	defer pool.Close()
+	defer pool.ManageObject("at_snapshot_registry").Remove()
+
+	object := pool.ManageObject("at_snapshot")
+	handleError(t, object.WriteFull(bytes.NewBufferString("before")))
+	defer object.Remove()
+
+	registry := pool.SnapshotRegistry("at_snapshot_registry")
+	snapshot, err := registry.Create("snap")
+	handleError(t, err)
+	if snapshot == nil {
+		return
+	}
+	defer registry.Remove("snap")
+	handleError(t, object.WriteFull(bytes.NewBufferString("after")))
+
+	pool.SetAlignmentPolicy(PadMisaligned)
+	defer pool.SetAlignmentPolicy(RejectMisaligned)
+	view, err := pool.AtSnapshot(snapshot.Id)
+	handleError(t, err)
+	if view == nil {
+		return
+	}
+	defer view.Close()
+	if view.pool.alignment != PadMisaligned {
+		t.Error("view should copy the alignment policy of the pool")
+	}
+
+	data, err := view.ManageObject("at_snapshot").Read(6, 0)
+	handleError(t, err)
+	if value := string(readAll(data)); value != "before" {
+		t.Errorf("view should read before, reads %s", value)
+	}
+
+	err = view.ManageObject("at_snapshot").WriteFull(bytes.NewBufferString("view"))
+	if radosErr, ok := err.(*RadosError); !ok || radosErr.Code != -int(syscall.EROFS) {
+		t.Errorf("writing through the view should fail with EROFS, fails with %v", err)
+	}
+
+	data, err = object.Read(6, 0)
+	handleError(t, err)
+	if value := string(readAll(data)); value != "after" {
+		t.Errorf("pool should still read after, reads %s", value)
+	}
+}
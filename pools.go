@@ -47,7 +47,8 @@ func (ps *PoolStatus) String() string {
 
 // Pool represents a pool io context. This contains pool related functions.
 type Pool struct {
-	context C.rados_ioctx_t
+	context   C.rados_ioctx_t
+	alignment AlignmentPolicy
 }
 
 // ManagePool opens a pool for query, read, and write operations.
@@ -60,7 +61,7 @@ func (cluster *Cluster) ManagePool(poolName string) (*Pool, error) {
 		err.Message = fmt.Sprintf("Unable to create IO Context for %s.", poolName)
 		return nil, err
 	}
-	return &Pool{ioContext}, nil
+	return &Pool{context: ioContext}, nil
 }
 
 // CloseWhenDone this pool context when all asynchronous writes are done.
@@ -160,11 +161,26 @@ func (pool *Pool) RequiresAlignment() bool {
 	return ret != 0
 }
 
-// TODO:
+// RequiredAlignment returns the size appends must be a multiple of if the pool requires alignment. Erasure coded pools
+// without overwrites require alignment.
 func (pool *Pool) RequiredAlignment() uint64 {
 	return uint64(C.rados_ioctx_pool_required_alignment(pool.context))
 }
 
+// AlignmentPolicy decides how appends that are not a multiple of the required alignment of a pool are handled.
+type AlignmentPolicy int
+
+const (
+	RejectMisaligned AlignmentPolicy = iota // Fail misaligned appends before sending them to the cluster.
+	PadMisaligned                           // Pad misaligned appends with zeros up to the alignment.
+)
+
+// SetAlignmentPolicy sets how misaligned appends to objects managed from now on are handled. Appends are never changed
+// on pools that do not require alignment. The default is RejectMisaligned.
+func (pool *Pool) SetAlignmentPolicy(policy AlignmentPolicy) {
+	pool.alignment = policy
+}
+
 // ListPools returns all the pools in the ceph cluster.
 func (cluster *Cluster) ListPools() ([]string, error) {
 	bufLen := 4096
@@ -282,3 +298,93 @@ func (cluster *Cluster) DeletePool(poolName string) error {
 	}
 	return nil
 }
+
+// PoolExists returns true if a pool with the given name exists. An error is returned if the pool could not be looked
+// up.
+func (cluster *Cluster) PoolExists(poolName string) (bool, error) {
+	p := C.CString(poolName)
+	defer freeString(p)
+	ret := C.rados_pool_lookup(cluster.handle, p)
+	if ret == -C.int64_t(syscall.ENOENT) {
+		return false, nil
+	}
+	if ret < 0 {
+		err := toRadosError(C.int(ret))
+		err.Message = fmt.Sprintf("Unable to look up %s pool.", poolName)
+		return false, err
+	}
+	return true, nil
+}
+
+// RenamePool renames a pool. Clients using the old name must reopen the pool.
+func (cluster *Cluster) RenamePool(oldName, newName string) error {
+	args := map[string]interface{}{
+		"prefix":   "osd pool rename",
+		"srcpool":  oldName,
+		"destpool": newName,
+	}
+	return cluster.monCommand(args, nil)
+}
+
+// DeletePoolSafely removes a pool from the cluster after checking it exists, has no pool snapshots and holds no
+// objects. Pools with snapshots or objects are only removed if force is true. Object counts are taken from the pool
+// statistics, which may lag behind very recent writes, and from a listing of the default namespace.
+func (cluster *Cluster) DeletePoolSafely(poolName string, force bool) error {
+	exists, err := cluster.PoolExists(poolName)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		err := toRadosError(-C.int(syscall.ENOENT))
+		err.Message = fmt.Sprintf("Unable to delete %s pool. The pool does not exist.", poolName)
+		return err
+	}
+	if !force {
+		pool, err := cluster.ManagePool(poolName)
+		if err != nil {
+			return err
+		}
+		reason, err := pool.deleteBlocker()
+		pool.Close()
+		if err != nil {
+			return err
+		}
+		if reason != "" {
+			err := toRadosError(-C.int(syscall.ENOTEMPTY))
+			err.Message = fmt.Sprintf("Refusing to delete %s pool. %s", poolName, reason)
+			return err
+		}
+	}
+	return cluster.DeletePool(poolName)
+}
+
+// deleteBlocker returns why the pool should not be deleted, or an empty string if it can be.
+func (pool *Pool) deleteBlocker() (string, error) {
+	snapshots, err := pool.ListPoolSnapshots()
+	if err != nil {
+		return "", err
+	}
+	if len(snapshots) > 0 {
+		return fmt.Sprintf("The pool has %d snapshots.", len(snapshots)), nil
+	}
+
+	status, err := pool.Status()
+	if err != nil {
+		return "", err
+	}
+	if status.Objects > 0 {
+		return fmt.Sprintf("The pool has %d objects.", status.Objects), nil
+	}
+
+	objects, err := pool.OpenObjectList()
+	if err != nil {
+		return "", err
+	}
+	defer objects.Close()
+	if object, _, err := objects.Next(); err == nil {
+		return fmt.Sprintf("The pool has objects, eg. %s.", object.name), nil
+	} else if radosErr, ok := err.(*RadosError); !ok || radosErr.Code != -int(syscall.ENOENT) {
+		return "", err
+	}
+	return "", nil
+}
@@ -1,6 +1,10 @@
 package grados
 
-import "testing"
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
 
 func TestGetConfigReference(t *testing.T) {
 	cluster := connect(t)
@@ -204,3 +208,135 @@ func TestName(t *testing.T) {
 	pool.Close()
 	cluster.Shutdown()
 }
+
+func TestPoolProperties(t *testing.T) {
+	cluster := connect(t)
+	if cluster == nil {
+		return
+	}
+	defer cluster.Shutdown()
+
+	handleError(t, cluster.CreatePool("propertiesTest"))
+	defer cluster.DeletePool("propertiesTest")
+
+	pool, err := cluster.ManagePool("propertiesTest")
+	handleError(t, err)
+	if pool == nil {
+		return
+	}
+	defer pool.Close()
+
+	handleError(t, pool.SetMinSize(1))
+	handleError(t, pool.SetCompressionMode(CompressionAggressive))
+	handleError(t, pool.SetCompressionRequiredRatio(0.5))
+
+	properties, err := pool.Properties()
+	handleError(t, err)
+	if properties == nil {
+		return
+	}
+	if properties.MinSize != 1 {
+		t.Errorf("min_size should be 1, is %d", properties.MinSize)
+	}
+	if properties.CompressionMode != CompressionAggressive {
+		t.Errorf("compression_mode should be aggressive, is %s", properties.CompressionMode)
+	}
+	if properties.CompressionRequiredRatio != 0.5 {
+		t.Errorf("compression_required_ratio should be 0.5, is %f", properties.CompressionRequiredRatio)
+	}
+}
+
+func TestPoolQuotaAndApplications(t *testing.T) {
+	cluster := connect(t)
+	if cluster == nil {
+		return
+	}
+	defer cluster.Shutdown()
+
+	handleError(t, cluster.CreatePool("quotaTest"))
+	defer cluster.DeletePool("quotaTest")
+
+	pool, err := cluster.ManagePool("quotaTest")
+	handleError(t, err)
+	if pool == nil {
+		return
+	}
+	defer pool.Close()
+
+	handleError(t, pool.SetQuota(1<<20, 100))
+	quota, err := pool.Quota()
+	handleError(t, err)
+	if quota != nil && (quota.MaxBytes != 1<<20 || quota.MaxObjects != 100) {
+		t.Errorf("quota should be 1048576 bytes and 100 objects, is %+v", quota)
+	}
+
+	handleError(t, pool.EnableApplication("grados"))
+	handleError(t, pool.SetApplicationMetadata("grados", "tenant", "test"))
+	apps, err := pool.ListApplications()
+	handleError(t, err)
+	if len(apps) != 1 || apps[0] != "grados" {
+		t.Errorf("applications should be grados, are %v", apps)
+	}
+}
+
+func TestRenameAndDeletePoolSafely(t *testing.T) {
+	cluster := connect(t)
+	if cluster == nil {
+		return
+	}
+	defer cluster.Shutdown()
+
+	handleError(t, cluster.CreatePool("renameTest"))
+	handleError(t, cluster.RenamePool("renameTest", "renamedTest"))
+	defer cluster.DeletePool("renamedTest")
+
+	exists, err := cluster.PoolExists("renameTest")
+	handleError(t, err)
+	if exists {
+		t.Error("renameTest should not exist after the rename")
+	}
+	exists, err = cluster.PoolExists("renamedTest")
+	handleError(t, err)
+	if !exists {
+		t.Error("renamedTest should exist after the rename")
+	}
+
+	pool, err := cluster.ManagePool("renamedTest")
+	handleError(t, err)
+	if pool == nil {
+		return
+	}
+	handleError(t, pool.ManageObject("object").WriteFull(bytes.NewBufferString("data")))
+	pool.Close()
+
+	if err := cluster.DeletePoolSafely("renamedTest", false); err == nil {
+		t.Error("deleting a non-empty pool should fail unless forced")
+	}
+	handleError(t, cluster.DeletePoolSafely("renamedTest", true))
+	exists, err = cluster.PoolExists("renamedTest")
+	handleError(t, err)
+	if exists {
+		t.Error("renamedTest should not exist after the delete")
+	}
+
+	if err := cluster.DeletePoolSafely("missingTest", true); err == nil {
+		t.Error("deleting a missing pool should fail")
+	}
+
+	// an empty pool with a snapshot is refused because of the snapshot
+	handleError(t, cluster.CreatePool("snapshotDeleteTest"))
+	defer cluster.DeletePool("snapshotDeleteTest")
+	pool, err = cluster.ManagePool("snapshotDeleteTest")
+	handleError(t, err)
+	if pool == nil {
+		return
+	}
+	handleError(t, pool.CreatePoolSnapshot("snapshot"))
+	pool.Close()
+
+	err = cluster.DeletePoolSafely("snapshotDeleteTest", false)
+	if err == nil || !strings.Contains(err.Error(), "snapshots") {
+		t.Errorf("deleting a pool with snapshots should fail unless forced, fails with %v", err)
+	}
+	handleError(t, cluster.DeletePoolSafely("snapshotDeleteTest", true))
+}
@@ -0,0 +1,114 @@
+package grados
+
+import (
+	"io"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// Message is a message published to a topic.
+type Message struct {
+	Topic       string // The topic the message was published to.
+	PublisherId uint64 // The instance id of the publishing client.
+	Payload     []byte // The content of the message.
+	Gap         bool   // Messages may have been lost before this one. Gap messages carry no payload.
+}
+
+// Publish sends the payload to the subscribers of the topic and waits for them to acknowledge it until the timeout
+// expires. The returned result lists the subscribers that acknowledged the message and the ones that timed out. A topic
+// without subscribers returns an empty result.
+func (pool *Pool) Publish(topic string, payload io.Reader, timeout time.Duration) (*NotifyResult, error) {
+	result, err := pool.ManageObject(topic).Notify(payload, timeout)
+	if radosErr, ok := err.(*RadosError); ok && radosErr.Code == -int(syscall.ENOENT) {
+		return &NotifyResult{Acks: make([]*NotifyAck, 0), Timeouts: make([]*NotifyTimeout, 0)}, nil
+	}
+	return result, err
+}
+
+// Subscription receives the messages published to a topic. Use Subscribe from a pool to create a valid instance.
+type Subscription struct {
+	object   *Object
+	messages chan *Message
+	watch    *ResilientWatch
+
+	done      chan struct{}
+	closeOnce sync.Once
+	wg        sync.WaitGroup
+}
+
+// Subscribe starts receiving the messages published to the topic. The topic object is created if it does not exist.
+// The topic is watched again after connection errors, and messages published in the meantime are lost. Lost messages
+// are reported by a message with Gap set.
+func (pool *Pool) Subscribe(topic string) (*Subscription, error) {
+	object := pool.ManageObject(topic)
+	if err := NewWriteTransaction().CreateObject(CreateIdempotent, "").Operate(object, nil); err != nil {
+		return nil, err
+	}
+	watch, err := object.WatchResilient()
+	if err != nil {
+		return nil, err
+	}
+	s := &Subscription{
+		object:   object,
+		messages: make(chan *Message, 64),
+		watch:    watch,
+		done:     make(chan struct{}),
+	}
+	s.wg.Add(1)
+	go s.run()
+	return s, nil
+}
+
+// Messages returns the channel of messages published to the topic.
+func (s *Subscription) Messages() <-chan *Message {
+	return s.messages
+}
+
+// Close stops the subscription.
+func (s *Subscription) Close() error {
+	var err error
+	s.closeOnce.Do(func() {
+		close(s.done)
+		s.wg.Wait()
+		err = s.watch.Close()
+	})
+	return err
+}
+
+// run forwards the notifications of the watch as messages.
+func (s *Subscription) run() {
+	defer s.wg.Done()
+	for {
+		select {
+		case <-s.done:
+			return
+		case event := <-s.watch.Events():
+			message := s.message(event)
+			if message == nil {
+				continue
+			}
+			select {
+			case s.messages <- message:
+			case <-s.done:
+				return
+			}
+		}
+	}
+}
+
+// message converts a watch event to a message. Reconnections and dropped notifications are converted to gap messages.
+// This returns nil for events that are not forwarded.
+func (s *Subscription) message(event *WatchEvent) *Message {
+	switch event.Type {
+	case WatchNotification:
+		return &Message{
+			Topic:       s.object.name,
+			PublisherId: event.Notification.NotifierId,
+			Payload:     event.Notification.Data,
+		}
+	case WatchReconnected, WatchDropped:
+		return &Message{Topic: s.object.name, Gap: true}
+	}
+	return nil
+}
@@ -0,0 +1,66 @@
+package grados
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+func TestPublishSubscribe(t *testing.T) {
+	cluster := connect(t)
+	if cluster == nil {
+		return
+	}
+	defer cluster.Shutdown()
+
+	pool, err := cluster.ManagePool("data")
+	handleError(t, err)
+	if pool == nil {
+		return
+	}
+	defer pool.Close()
+	defer pool.ManageObject("pubsub_topic").Remove()
+
+	subscription, err := pool.Subscribe("pubsub_topic")
+	handleError(t, err)
+	if subscription == nil {
+		return
+	}
+	defer subscription.Close()
+
+	result, err := pool.Publish("pubsub_topic", bytes.NewBufferString("hello"), 5*time.Second)
+	handleError(t, err)
+	if result != nil && len(result.Acks) != 1 {
+		t.Errorf("should have 1 ack, has %d", len(result.Acks))
+	}
+
+	select {
+	case message := <-subscription.Messages():
+		if string(message.Payload) != "hello" {
+			t.Errorf("payload should be hello, payload is %s", message.Payload)
+		}
+	case <-time.After(5 * time.Second):
+		t.Error("message should have been received")
+	}
+
+	result, err = pool.Publish("pubsub_nobody", bytes.NewBufferString("hello"), time.Second)
+	handleError(t, err)
+	if result != nil && len(result.Acks) != 0 {
+		t.Errorf("should have no ack, has %d", len(result.Acks))
+	}
+}
+
+func TestSubscriptionGap(t *testing.T) {
+	s := &Subscription{object: &Object{name: "topic"}}
+
+	message := s.message(&WatchEvent{Type: WatchNotification, Notification: &Notification{NotifierId: 1, Data: []byte("data")}})
+	if message == nil || message.Gap || string(message.Payload) != "data" || message.PublisherId != 1 {
+		t.Errorf("unexpected message %+v", message)
+	}
+	for _, eventType := range []WatchEventType{WatchReconnected, WatchDropped} {
+		message = s.message(&WatchEvent{Type: eventType})
+		if message == nil || !message.Gap || message.Topic != "topic" || message.Payload != nil {
+			t.Errorf("event %d should be a gap message, got %+v", eventType, message)
+		}
+	}
+}
@@ -17,9 +17,14 @@ type ReadOperation struct {
 	buffer    *C.char
 	bytesRead C.size_t
 	retVal    C.int
+	checksum  *checksumResult
 }
 
 func (pool *Pool) CreateReadOperation() (*ReadOperation, error) {
+	return newReadOperation(pool.context)
+}
+
+func newReadOperation(ioContext C.rados_ioctx_t) (*ReadOperation, error) {
 	opContext := C.rados_create_read_op()
 	if opContext == nil {
 		err := toRadosError(-1)
@@ -27,7 +32,7 @@ func (pool *Pool) CreateReadOperation() (*ReadOperation, error) {
 		return nil, err
 	}
 	ro := &ReadOperation{
-		ioContext: pool.context,
+		ioContext: ioContext,
 		opContext: opContext,
 	}
 	return ro, nil
@@ -76,6 +81,15 @@ func (ro *ReadOperation) Operate(object *Object, flags ...LibradosOperation) (io
 		err.Message = fmt.Sprintf("Unable to perform read operations on object %s.", object.name)
 		return nil, err
 	}
+	if ro.checksum != nil {
+		if err := toRadosError(ro.checksum.retVal); err != nil {
+			err.Message = fmt.Sprintf("Unable to checksum object %s.", object.name)
+			return nil, err
+		}
+	}
+	if ro.buffer == nil {
+		return nil, nil
+	}
 	if err := toRadosError(ro.retVal); err != nil {
 		err.Message = fmt.Sprintf("Unable to read from object %s.", object.name)
 		return nil, err
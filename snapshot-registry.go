@@ -0,0 +1,231 @@
+package grados
+
+/*
+#cgo LDFLAGS: -lrados
+#include <rados/librados.h>
+*/
+import "C"
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+const (
+	registrySnapshotPrefix = "snapshot."     // The omap key prefix of the registered snapshots.
+	registrySeqKey         = "write_ctx.seq" // The omap key of the write context sequence.
+)
+
+// RegisteredSnapshot is a self managed snapshot tracked by a SnapshotRegistry.
+type RegisteredSnapshot struct {
+	Id      SnapshotId `json:"id"`
+	Name    string     `json:"name"`
+	Created time.Time  `json:"created"`
+}
+
+// SnapshotRegistry keeps track of the self managed snapshots of a pool. The snapshot ids, names, creation times and the
+// write context sequence are persisted in the omap of an object so a restarted process can rebuild the snapshot
+// context with Restore. The registry is safe for concurrent use within a process, but concurrent writers in different
+// processes must coordinate externally. Use SnapshotRegistry from a pool to create a valid instance.
+type SnapshotRegistry struct {
+	pool   *Pool
+	object *Object
+	mutex  sync.Mutex
+}
+
+// SnapshotRegistry returns the registry of self managed snapshots persisted in the given object.
+func (pool *Pool) SnapshotRegistry(objectName string) *SnapshotRegistry {
+	return &SnapshotRegistry{
+		pool:   pool,
+		object: pool.ManageObject(objectName),
+	}
+}
+
+// Create creates a self managed snapshot with the given name, registers it and sets the snapshot context for writes to
+// the pool so new writes preserve it.
+func (r *SnapshotRegistry) Create(name string) (*RegisteredSnapshot, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	snapshots, seq, err := r.load()
+	if err != nil {
+		return nil, err
+	}
+	for _, snapshot := range snapshots {
+		if snapshot.Name == name {
+			err := toRadosError(-C.int(syscall.EEXIST))
+			err.Message = fmt.Sprintf("Snapshot %s is already registered.", name)
+			return nil, err
+		}
+	}
+
+	managed, err := r.pool.CreateSelfManagedSnapshot()
+	if err != nil {
+		return nil, err
+	}
+	snapshot := &RegisteredSnapshot{
+		Id:      managed.Id,
+		Name:    name,
+		Created: time.Now().UTC(),
+	}
+	if snapshot.Id > seq {
+		seq = snapshot.Id
+	}
+	value, _ := json.Marshal(snapshot)
+	update := map[string][]byte{
+		registrySnapshotPrefix + name: value,
+		registrySeqKey:                []byte(strconv.FormatUint(uint64(seq), 10)),
+	}
+	if err := r.object.updateOmap(update, nil); err != nil {
+		managed.Remove()
+		return nil, err
+	}
+	if err := r.pool.setWriteContext(seq, append(snapshots, snapshot)); err != nil {
+		return nil, err
+	}
+	return snapshot, nil
+}
+
+// List returns the registered snapshots ordered by id.
+func (r *SnapshotRegistry) List() ([]*RegisteredSnapshot, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	snapshots, _, err := r.load()
+	return snapshots, err
+}
+
+// Lookup returns the registered snapshot with the given name.
+func (r *SnapshotRegistry) Lookup(name string) (*RegisteredSnapshot, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	snapshots, _, err := r.load()
+	if err != nil {
+		return nil, err
+	}
+	for _, snapshot := range snapshots {
+		if snapshot.Name == name {
+			return snapshot, nil
+		}
+	}
+	notFound := toRadosError(-C.int(syscall.ENOENT))
+	notFound.Message = fmt.Sprintf("Snapshot %s is not registered.", name)
+	return nil, notFound
+}
+
+// Seq returns the persisted sequence of the write context.
+func (r *SnapshotRegistry) Seq() (SnapshotId, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	_, seq, err := r.load()
+	return seq, err
+}
+
+// Remove removes the self managed snapshot with the given name, unregisters it and updates the snapshot context for
+// writes to the pool.
+func (r *SnapshotRegistry) Remove(name string) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	snapshots, seq, err := r.load()
+	if err != nil {
+		return err
+	}
+	remaining := make([]*RegisteredSnapshot, 0, len(snapshots))
+	var removed *RegisteredSnapshot
+	for _, snapshot := range snapshots {
+		if snapshot.Name == name {
+			removed = snapshot
+		} else {
+			remaining = append(remaining, snapshot)
+		}
+	}
+	if removed == nil {
+		err := toRadosError(-C.int(syscall.ENOENT))
+		err.Message = fmt.Sprintf("Snapshot %s is not registered.", name)
+		return err
+	}
+
+	managed := &ManagedSnapshot{Id: removed.Id, ioContext: r.pool.context}
+	if err := managed.Remove(); err != nil {
+		return err
+	}
+	if err := r.object.updateOmap(nil, []string{registrySnapshotPrefix + name}); err != nil {
+		return err
+	}
+	return r.pool.setWriteContext(seq, remaining)
+}
+
+// Restore sets the snapshot context for writes to the pool from the registered snapshots. This should be called by a
+// restarted process before writing.
+func (r *SnapshotRegistry) Restore() error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	snapshots, seq, err := r.load()
+	if err != nil {
+		return err
+	}
+	return r.pool.setWriteContext(seq, snapshots)
+}
+
+// load reads the registered snapshots ordered by id and the write context sequence. An empty registry is returned if
+// the object does not exist.
+func (r *SnapshotRegistry) load() ([]*RegisteredSnapshot, SnapshotId, error) {
+	entries, err := r.object.omap("")
+	if radosErr, ok := err.(*RadosError); ok && radosErr.Code == -int(syscall.ENOENT) {
+		return make([]*RegisteredSnapshot, 0), 0, nil
+	}
+	if err != nil {
+		return nil, 0, err
+	}
+
+	snapshots := make([]*RegisteredSnapshot, 0, len(entries))
+	var seq SnapshotId
+	for key, value := range entries {
+		if key == registrySeqKey {
+			s, err := strconv.ParseUint(string(value), 10, 64)
+			if err != nil {
+				err := toRadosError(-C.int(syscall.EINVAL))
+				err.Message = fmt.Sprintf("Invalid write context sequence %q in registry %s.", value, r.object.name)
+				return nil, 0, err
+			}
+			seq = SnapshotId(s)
+			continue
+		}
+		if !strings.HasPrefix(key, registrySnapshotPrefix) {
+			continue
+		}
+		snapshot := new(RegisteredSnapshot)
+		if jsonErr := json.Unmarshal(value, snapshot); jsonErr != nil {
+			err := toRadosError(-C.int(syscall.EINVAL))
+			err.Message = fmt.Sprintf("Invalid snapshot %s in registry %s: %s.", key, r.object.name, jsonErr)
+			return nil, 0, err
+		}
+		snapshots = append(snapshots, snapshot)
+	}
+	sort.Slice(snapshots, func(i, j int) bool { return snapshots[i].Id < snapshots[j].Id })
+	return snapshots, seq, nil
+}
+
+// setWriteContext sets the snapshot context for writes to the pool. librados expects the snapshots in descending order.
+func (pool *Pool) setWriteContext(seq SnapshotId, snapshots []*RegisteredSnapshot) error {
+	ids := make([]C.rados_snap_t, len(snapshots))
+	for i, snapshot := range snapshots {
+		ids[len(snapshots)-1-i] = C.rados_snap_t(snapshot.Id)
+	}
+	var idsAddr *C.rados_snap_t
+	if len(ids) > 0 {
+		idsAddr = &ids[0]
+	}
+	ret := C.rados_ioctx_selfmanaged_snap_set_write_ctx(pool.context, C.rados_snap_t(seq), idsAddr, C.int(len(ids)))
+	if err := toRadosError(ret); err != nil {
+		err.Message = "Unable to set snapshot context for writing objects."
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,60 @@
+package grados
+
+import (
+	"testing"
+)
+
+func TestSnapshotRegistry(t *testing.T) {
+	cluster := connect(t)
+	if cluster == nil {
+		return
+	}
+	defer cluster.Shutdown()
+
+	pool, err := cluster.ManagePool("data")
+	handleError(t, err)
+	if pool == nil {
+		return
+	}
+	defer pool.Close()
+	defer pool.ManageObject("snapshot_registry").Remove()
+
+	registry := pool.SnapshotRegistry("snapshot_registry")
+	first, err := registry.Create("first")
+	handleError(t, err)
+	second, err := registry.Create("second")
+	handleError(t, err)
+	if first == nil || second == nil {
+		return
+	}
+
+	if _, err := registry.Create("first"); err == nil {
+		t.Error("creating a duplicate snapshot should fail")
+	}
+
+	// a new registry on the same object should see the persisted snapshots
+	restored := pool.SnapshotRegistry("snapshot_registry")
+	handleError(t, restored.Restore())
+	snapshots, err := restored.List()
+	handleError(t, err)
+	if len(snapshots) != 2 || snapshots[0].Name != "first" || snapshots[1].Name != "second" {
+		t.Errorf("should list first and second, lists %+v", snapshots)
+	}
+	seq, err := restored.Seq()
+	handleError(t, err)
+	if seq != second.Id {
+		t.Errorf("seq should be %d, is %d", second.Id, seq)
+	}
+
+	snapshot, err := restored.Lookup("first")
+	handleError(t, err)
+	if snapshot != nil && snapshot.Id != first.Id {
+		t.Errorf("first should have id %d, has %d", first.Id, snapshot.Id)
+	}
+
+	handleError(t, restored.Remove("first"))
+	handleError(t, restored.Remove("second"))
+	if _, err := restored.Lookup("first"); err == nil {
+		t.Error("removed snapshot should not be found")
+	}
+}
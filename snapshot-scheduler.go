@@ -0,0 +1,193 @@
+package grados
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"syscall"
+	"time"
+)
+
+// RetentionPolicy decides which scheduled pool snapshots are kept. The newest snapshot of each of the last Hourly hours,
+// Daily days and Weekly weeks is kept and the others are removed. A policy keeping nothing keeps all snapshots.
+type RetentionPolicy struct {
+	Hourly int // The number of hours to keep a snapshot for.
+	Daily  int // The number of days to keep a snapshot for.
+	Weekly int // The number of weeks to keep a snapshot for.
+}
+
+// SnapshotSchedule configures a SnapshotScheduler.
+type SnapshotSchedule struct {
+	// NameTemplate is the time layout, as used by time.Format, of the names of the snapshots, eg.
+	// "auto-2006-01-02T15:04". Only the snapshots whose name parses with the template are pruned.
+	NameTemplate string
+	Interval     time.Duration   // Snapshots are created at every multiple of the interval.
+	Retention    RetentionPolicy // Which snapshots are kept.
+	Location     *time.Location  // The time zone of the names and of the retention buckets. UTC is used if nil.
+	DryRun       bool            // Only report the snapshots that would be created and removed.
+
+	// OnRun is called with the report of every run. This can be nil.
+	OnRun func(report *ScheduleReport, err error)
+}
+
+// ScheduleReport is the result of a run of a SnapshotScheduler.
+type ScheduleReport struct {
+	Time    time.Time // When the run happened.
+	DryRun  bool      // True if nothing was changed.
+	Created string    // The name of the created snapshot. Empty if no snapshot was due.
+	Removed []string  // The names of the pruned snapshots.
+	Kept    []string  // The names of the scheduled snapshots kept by the retention policy.
+}
+
+// SnapshotScheduler creates pool snapshots on a schedule and prunes them according to a retention policy. Use
+// ScheduleSnapshots from a pool to create a valid instance.
+type SnapshotScheduler struct {
+	pool     *Pool
+	schedule *SnapshotSchedule
+}
+
+// ScheduleSnapshots returns a scheduler of the pool snapshots.
+func (pool *Pool) ScheduleSnapshots(schedule *SnapshotSchedule) *SnapshotScheduler {
+	return &SnapshotScheduler{
+		pool:     pool,
+		schedule: schedule,
+	}
+}
+
+// Run creates and prunes snapshots at every interval until the context is done. This is meant to be run in its own
+// goroutine. The reports of the runs are passed to OnRun.
+func (s *SnapshotScheduler) Run(ctx context.Context) error {
+	if s.schedule.Interval <= 0 {
+		return &RadosError{Code: -int(syscall.EINVAL), Message: "Snapshot schedule interval must be greater than 0."}
+	}
+	for {
+		now := time.Now()
+		next := now.Truncate(s.schedule.Interval).Add(s.schedule.Interval)
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(next.Sub(now)):
+		}
+		report, err := s.RunOnce(next)
+		if s.schedule.OnRun != nil {
+			s.schedule.OnRun(report, err)
+		}
+	}
+}
+
+// RunOnce creates the snapshot for the given time unless it exists and prunes the snapshots that are not retained.
+func (s *SnapshotScheduler) RunOnce(now time.Time) (*ScheduleReport, error) {
+	now = now.In(s.location())
+	report := &ScheduleReport{
+		Time:    now,
+		DryRun:  s.schedule.DryRun,
+		Removed: make([]string, 0),
+		Kept:    make([]string, 0),
+	}
+
+	snapshots, err := s.list()
+	if err != nil {
+		return report, err
+	}
+
+	name := now.Format(s.schedule.NameTemplate)
+	exists := false
+	for _, snapshot := range snapshots {
+		if snapshot.Name == name {
+			exists = true
+		}
+	}
+	if !exists {
+		if !s.schedule.DryRun {
+			if err := s.pool.CreatePoolSnapshot(name); err != nil {
+				return report, err
+			}
+		}
+		report.Created = name
+		snapshots = append(snapshots, &PoolSnapshot{Name: name, Timestamp: now})
+	}
+
+	keep, remove := s.schedule.Retention.apply(snapshots, s.location())
+	for _, snapshot := range keep {
+		report.Kept = append(report.Kept, snapshot.Name)
+	}
+	for _, snapshot := range remove {
+		if !s.schedule.DryRun {
+			if err := s.pool.RemovePoolSnapshot(snapshot.Name); err != nil {
+				return report, err
+			}
+		}
+		report.Removed = append(report.Removed, snapshot.Name)
+	}
+	return report, nil
+}
+
+// list returns the pool snapshots whose name matches the template.
+func (s *SnapshotScheduler) list() ([]*PoolSnapshot, error) {
+	all, err := s.pool.ListPoolSnapshots()
+	if err != nil {
+		return nil, err
+	}
+	snapshots := make([]*PoolSnapshot, 0, len(all))
+	for _, snapshot := range all {
+		if _, err := time.ParseInLocation(s.schedule.NameTemplate, snapshot.Name, s.location()); err == nil {
+			snapshots = append(snapshots, snapshot)
+		}
+	}
+	return snapshots, nil
+}
+
+func (s *SnapshotScheduler) location() *time.Location {
+	if s.schedule.Location == nil {
+		return time.UTC
+	}
+	return s.schedule.Location
+}
+
+// apply splits the snapshots into the ones kept by the policy and the ones to remove, both ordered from newest to
+// oldest.
+func (policy RetentionPolicy) apply(snapshots []*PoolSnapshot, location *time.Location) ([]*PoolSnapshot, []*PoolSnapshot) {
+	sorted := make([]*PoolSnapshot, len(snapshots))
+	copy(sorted, snapshots)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Timestamp.After(sorted[j].Timestamp) })
+	if policy.Hourly <= 0 && policy.Daily <= 0 && policy.Weekly <= 0 {
+		return sorted, make([]*PoolSnapshot, 0)
+	}
+
+	kept := make(map[*PoolSnapshot]bool)
+	buckets := []struct {
+		count  int
+		bucket func(t time.Time) string
+	}{
+		{policy.Hourly, func(t time.Time) string { return t.Format("2006-01-02T15") }},
+		{policy.Daily, func(t time.Time) string { return t.Format("2006-01-02") }},
+		{policy.Weekly, func(t time.Time) string {
+			year, week := t.ISOWeek()
+			return fmt.Sprintf("%d-%d", year, week)
+		}},
+	}
+	for _, b := range buckets {
+		seen := make(map[string]bool)
+		for _, snapshot := range sorted {
+			if len(seen) >= b.count {
+				break
+			}
+			bucket := b.bucket(snapshot.Timestamp.In(location))
+			if !seen[bucket] {
+				seen[bucket] = true
+				kept[snapshot] = true
+			}
+		}
+	}
+
+	keep := make([]*PoolSnapshot, 0)
+	remove := make([]*PoolSnapshot, 0)
+	for _, snapshot := range sorted {
+		if kept[snapshot] {
+			keep = append(keep, snapshot)
+		} else {
+			remove = append(remove, snapshot)
+		}
+	}
+	return keep, remove
+}
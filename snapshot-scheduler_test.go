@@ -0,0 +1,64 @@
+package grados
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRetentionPolicy(t *testing.T) {
+	start := time.Date(2016, 3, 1, 0, 0, 0, 0, time.UTC)
+	snapshots := make([]*PoolSnapshot, 0)
+	// a snapshot every 30 minutes for 10 days
+	for i := 0; i < 480; i++ {
+		timestamp := start.Add(time.Duration(i) * 30 * time.Minute)
+		snapshots = append(snapshots, &PoolSnapshot{Name: timestamp.Format(time.RFC3339), Timestamp: timestamp})
+	}
+
+	keep, remove := RetentionPolicy{Hourly: 3, Daily: 2, Weekly: 2}.apply(snapshots, time.UTC)
+	// the 3 newest hours, the newest of the day before, and the newest of the week before
+	if len(keep) != 5 {
+		t.Errorf("should keep 5 snapshots, keeps %d", len(keep))
+	}
+	if len(keep)+len(remove) != len(snapshots) {
+		t.Errorf("should split all %d snapshots, splits %d", len(snapshots), len(keep)+len(remove))
+	}
+	if len(keep) > 0 && keep[0] != snapshots[len(snapshots)-1] {
+		t.Error("newest snapshot should be kept first")
+	}
+
+	keep, remove = RetentionPolicy{}.apply(snapshots, time.UTC)
+	if len(keep) != len(snapshots) || len(remove) != 0 {
+		t.Error("empty policy should keep all snapshots")
+	}
+}
+
+func TestScheduleSnapshotsDryRun(t *testing.T) {
+	cluster := connect(t)
+	if cluster == nil {
+		return
+	}
+	defer cluster.Shutdown()
+
+	pool, err := cluster.ManagePool("data")
+	handleError(t, err)
+	if pool == nil {
+		return
+	}
+	defer pool.Close()
+
+	scheduler := pool.ScheduleSnapshots(&SnapshotSchedule{
+		NameTemplate: "grados-test-2006-01-02T15:04",
+		Interval:     time.Hour,
+		Retention:    RetentionPolicy{Hourly: 1},
+		DryRun:       true,
+	})
+	now := time.Date(2016, 3, 1, 12, 0, 0, 0, time.UTC)
+	report, err := scheduler.RunOnce(now)
+	handleError(t, err)
+	if report.Created != "grados-test-2016-03-01T12:00" {
+		t.Errorf("should create grados-test-2016-03-01T12:00, creates %s", report.Created)
+	}
+	if _, err := pool.LookupPoolSnapshot(report.Created); err == nil {
+		t.Error("dry run should not create the snapshot")
+	}
+}
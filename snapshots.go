@@ -92,21 +92,48 @@ func (snapshot *ManagedSnapshot) Remove() error {
 	return nil
 }
 
-// ListSelfManagedSnapshots returns a list of all self managed snapshots.
-func (pool *Pool) ListPoolSnapshots() []SnapshotId {
-	snaps := make([]SnapshotId, 0)
+// PoolSnapshot is a pool wide snapshot.
+type PoolSnapshot struct {
+	Id        SnapshotId // The id of the snapshot.
+	Name      string     // The name of the snapshot.
+	Timestamp time.Time  // When the snapshot was created.
+}
+
+// ListPoolSnapshots returns the pool wide snapshots with their names and creation times. Snapshots removed while they
+// are listed are skipped.
+func (pool *Pool) ListPoolSnapshots() ([]*PoolSnapshot, error) {
+	ids := make([]C.rados_snap_t, 16)
 	for {
-		var addr *C.rados_snap_t
-		if len(snaps) > 0 {
-			addr = (*C.rados_snap_t)(&snaps[0])
-		}
-		ret := C.rados_ioctx_snap_list(pool.context, addr, C.int(len(snaps)))
+		ret := C.rados_ioctx_snap_list(pool.context, &ids[0], C.int(len(ids)))
 		if int(ret) == -int(syscall.ERANGE) {
-			snaps = make([]SnapshotId, C.int(len(snaps))+1)
+			ids = make([]C.rados_snap_t, 2*len(ids))
+			continue
+		}
+		if err := toRadosError(ret); err != nil {
+			err.Message = "Unable to list pool snapshots."
+			return nil, err
+		}
+		ids = ids[:ret]
+		break
+	}
+
+	snapshots := make([]*PoolSnapshot, 0, len(ids))
+	for _, id := range ids {
+		snapshot := &PoolSnapshot{Id: SnapshotId(id)}
+		name, err := pool.ReverseLookupSnapshot(snapshot.Id)
+		if err == nil {
+			snapshot.Name = name
+			snapshot.Timestamp, err = pool.SnapshotTimestamp(snapshot.Id)
+		}
+		if radosErr, ok := err.(*RadosError); ok && radosErr.Code == -int(syscall.ENOENT) {
 			continue
 		}
-		return snaps
+		if err != nil {
+			return nil, err
+		}
+		snapshots = append(snapshots, snapshot)
 	}
+	return snapshots, nil
 }
 
 // CreateSnapshot creates a pool wide snapshot.
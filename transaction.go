@@ -0,0 +1,382 @@
+package grados
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"syscall"
+	"time"
+)
+
+// StepType identifies the kind of a transaction step.
+type StepType string
+
+const (
+	StepAssertExists     StepType = "assert_exists"     // Fails the transaction if the object does not exist.
+	StepCompareAttribute StepType = "compare_attribute" // Fails the transaction if the attribute comparison fails.
+	StepSetAttribute     StepType = "set_attribute"     // Sets an extended attribute.
+	StepRemoveAttribute  StepType = "remove_attribute"  // Removes an extended attribute.
+	StepCreateObject     StepType = "create_object"     // Creates the object.
+	StepWrite            StepType = "write"             // Writes data at an offset.
+	StepWriteFull        StepType = "write_full"        // Replaces the object data.
+	StepAppend           StepType = "append"            // Appends data to the object.
+	StepRemove           StepType = "remove"            // Removes the object.
+	StepTruncate         StepType = "truncate"          // Truncates the object.
+	StepZero             StepType = "zero"              // Zeroes a range of the object.
+	StepRead             StepType = "read"              // Reads a range of the object.
+	StepSparseRead       StepType = "sparse_read"       // Reads the allocated extents of a range of the object.
+)
+
+// TransactionStep is a single step of a transaction. Only the fields relevant to the step type are used.
+type TransactionStep struct {
+	Type     StepType         `json:"type"`
+	Name     string           `json:"name,omitempty"`
+	Operator CompareAttribute `json:"operator,omitempty"`
+	Data     []byte           `json:"data,omitempty"`
+	Offset   uint64           `json:"offset,omitempty"`
+	Length   uint64           `json:"length,omitempty"`
+	Mode     CreateMode       `json:"mode,omitempty"`
+	Category string           `json:"category,omitempty"`
+	Flags    LibradosOpFlag   `json:"flags,omitempty"` // The flags of the librados operation of the step.
+}
+
+// WriteTransaction is a pure Go description of a write operation. Unlike WriteOperation, it does not hold any librados
+// resources and can be inspected, validated, serialized (eg. with encoding/json) and applied to any number of objects.
+// The librados write operation is only created when Operate is called and is released right after.
+type WriteTransaction struct {
+	Steps []*TransactionStep `json:"steps"`
+
+	flagsWithoutStep bool
+}
+
+// NewWriteTransaction creates an empty write transaction.
+func NewWriteTransaction() *WriteTransaction {
+	return &WriteTransaction{
+		Steps: make([]*TransactionStep, 0),
+	}
+}
+
+// SetFlags sets the flags of the last step added to the transaction, like librados does for operations. A transaction
+// with flags set before any step is not valid.
+func (tx *WriteTransaction) SetFlags(flags ...LibradosOpFlag) *WriteTransaction {
+	if len(tx.Steps) == 0 {
+		tx.flagsWithoutStep = true
+		return tx
+	}
+	step := tx.Steps[len(tx.Steps)-1]
+	step.Flags = 0
+	for _, flag := range flags {
+		step.Flags |= flag
+	}
+	return tx
+}
+
+// AssertExists ensures the object exists before writing.
+func (tx *WriteTransaction) AssertExists() *WriteTransaction {
+	return tx.add(&TransactionStep{Type: StepAssertExists})
+}
+
+// CompareAttribute ensures the attribute value satisfies the comparison before writing.
+func (tx *WriteTransaction) CompareAttribute(name string, operator CompareAttribute, value io.Reader) *WriteTransaction {
+	return tx.add(&TransactionStep{Type: StepCompareAttribute, Name: name, Operator: operator, Data: readAll(value)})
+}
+
+// SetAttribute sets an extended attribute of the object.
+func (tx *WriteTransaction) SetAttribute(name string, value io.Reader) *WriteTransaction {
+	return tx.add(&TransactionStep{Type: StepSetAttribute, Name: name, Data: readAll(value)})
+}
+
+// RemoveAttribute removes an extended attribute of the object.
+func (tx *WriteTransaction) RemoveAttribute(name string) *WriteTransaction {
+	return tx.add(&TransactionStep{Type: StepRemoveAttribute, Name: name})
+}
+
+// CreateObject creates the object.
+func (tx *WriteTransaction) CreateObject(mode CreateMode, category string) *WriteTransaction {
+	return tx.add(&TransactionStep{Type: StepCreateObject, Mode: mode, Category: category})
+}
+
+// Write writes the data at a specific offset of the object.
+func (tx *WriteTransaction) Write(data io.Reader, offset uint64) *WriteTransaction {
+	return tx.add(&TransactionStep{Type: StepWrite, Data: readAll(data), Offset: offset})
+}
+
+// WriteFull replaces the object data.
+func (tx *WriteTransaction) WriteFull(data io.Reader) *WriteTransaction {
+	return tx.add(&TransactionStep{Type: StepWriteFull, Data: readAll(data)})
+}
+
+// Append appends the data to the object.
+func (tx *WriteTransaction) Append(data io.Reader) *WriteTransaction {
+	return tx.add(&TransactionStep{Type: StepAppend, Data: readAll(data)})
+}
+
+// Remove removes the object.
+func (tx *WriteTransaction) Remove() *WriteTransaction {
+	return tx.add(&TransactionStep{Type: StepRemove})
+}
+
+// Truncate truncates the object to the given size.
+func (tx *WriteTransaction) Truncate(size uint64) *WriteTransaction {
+	return tx.add(&TransactionStep{Type: StepTruncate, Offset: size})
+}
+
+// Zero zeroes a length of the object starting at the given offset.
+func (tx *WriteTransaction) Zero(offset, length uint64) *WriteTransaction {
+	return tx.add(&TransactionStep{Type: StepZero, Offset: offset, Length: length})
+}
+
+func (tx *WriteTransaction) add(step *TransactionStep) *WriteTransaction {
+	tx.Steps = append(tx.Steps, step)
+	return tx
+}
+
+// Validate checks that the transaction can be applied. An error is returned for empty transactions and malformed or
+// unsupported steps.
+func (tx *WriteTransaction) Validate() error {
+	if len(tx.Steps) == 0 {
+		return invalidTransaction("Write transaction has no steps.")
+	}
+	if tx.flagsWithoutStep {
+		return invalidTransaction("Write transaction has flags set before any step.")
+	}
+	for i, step := range tx.Steps {
+		switch step.Type {
+		case StepAssertExists, StepRemove, StepTruncate, StepWriteFull:
+		case StepCompareAttribute:
+			if step.Name == "" {
+				return invalidTransaction(fmt.Sprintf("Step %d: attribute name is required.", i))
+			}
+			if !step.Operator.valid() {
+				return invalidTransaction(fmt.Sprintf("Step %d: invalid compare operator %d.", i, step.Operator))
+			}
+		case StepSetAttribute, StepRemoveAttribute:
+			if step.Name == "" {
+				return invalidTransaction(fmt.Sprintf("Step %d: attribute name is required.", i))
+			}
+		case StepCreateObject:
+			if step.Mode != CreateExclusive && step.Mode != CreateIdempotent {
+				return invalidTransaction(fmt.Sprintf("Step %d: invalid create mode %d.", i, step.Mode))
+			}
+		case StepWrite, StepAppend:
+			if len(step.Data) == 0 {
+				return invalidTransaction(fmt.Sprintf("Step %d: no data to %s.", i, step.Type))
+			}
+		case StepZero:
+			if step.Length == 0 {
+				return invalidTransaction(fmt.Sprintf("Step %d: zero length must be greater than 0.", i))
+			}
+		default:
+			return invalidTransaction(fmt.Sprintf("Step %d: %s is not a write step.", i, step.Type))
+		}
+	}
+	return nil
+}
+
+// Operate validates the transaction and applies it to the object. This can be called any number of times on any number
+// of objects.
+func (tx *WriteTransaction) Operate(object *Object, modifiedTime *time.Time, flags ...LibradosOperation) error {
+	if err := tx.Validate(); err != nil {
+		return err
+	}
+	wo, err := newWriteOperation(object.ioContext, object.alignment)
+	if err != nil {
+		return err
+	}
+	defer wo.Release()
+	tx.compile(wo)
+	return wo.Operate(object, modifiedTime, flags...)
+}
+
+// compile adds the steps of the transaction to the librados write operation. The flags of a step are set right after
+// its operation is added since librados applies flags to the last operation. Appends rejected by the alignment policy
+// make the write operation fail when it is performed.
+func (tx *WriteTransaction) compile(wo *WriteOperation) {
+	for _, step := range tx.Steps {
+		switch step.Type {
+		case StepAssertExists:
+			wo.AssertExists()
+		case StepCompareAttribute:
+			wo.CompareAttribute(step.Name, step.Operator, bytes.NewReader(step.Data))
+		case StepSetAttribute:
+			wo.SetAttribute(step.Name, bytes.NewReader(step.Data))
+		case StepRemoveAttribute:
+			wo.RemoveAttribute(step.Name)
+		case StepCreateObject:
+			wo.CreateObject(step.Mode, step.Category)
+		case StepWrite:
+			wo.Write(bytes.NewReader(step.Data), step.Offset)
+		case StepWriteFull:
+			wo.WriteFull(bytes.NewReader(step.Data))
+		case StepAppend:
+			wo.Append(bytes.NewReader(step.Data))
+		case StepRemove:
+			wo.Remove()
+		case StepTruncate:
+			wo.Truncate(step.Offset)
+		case StepZero:
+			wo.Zero(step.Offset, step.Length)
+		}
+		if step.Flags != 0 {
+			wo.SetFlags(step.Flags)
+		}
+	}
+}
+
+// ReadTransaction is a pure Go description of a read operation. See WriteTransaction.
+type ReadTransaction struct {
+	Steps []*TransactionStep `json:"steps"`
+
+	flagsWithoutStep bool
+}
+
+// NewReadTransaction creates an empty read transaction.
+func NewReadTransaction() *ReadTransaction {
+	return &ReadTransaction{
+		Steps: make([]*TransactionStep, 0),
+	}
+}
+
+// SetFlags sets the flags of the last step added to the transaction, like librados does for operations. A transaction
+// with flags set before any step is not valid.
+func (tx *ReadTransaction) SetFlags(flags ...LibradosOpFlag) *ReadTransaction {
+	if len(tx.Steps) == 0 {
+		tx.flagsWithoutStep = true
+		return tx
+	}
+	step := tx.Steps[len(tx.Steps)-1]
+	step.Flags = 0
+	for _, flag := range flags {
+		step.Flags |= flag
+	}
+	return tx
+}
+
+// AssertExists ensures the object exists before reading.
+func (tx *ReadTransaction) AssertExists() *ReadTransaction {
+	return tx.add(&TransactionStep{Type: StepAssertExists})
+}
+
+// CompareAttribute ensures the attribute value satisfies the comparison before reading.
+func (tx *ReadTransaction) CompareAttribute(name string, operator CompareAttribute, value io.Reader) *ReadTransaction {
+	return tx.add(&TransactionStep{Type: StepCompareAttribute, Name: name, Operator: operator, Data: readAll(value)})
+}
+
+// Read reads a length of data from the object starting at the given offset.
+func (tx *ReadTransaction) Read(offset, length uint64) *ReadTransaction {
+	return tx.add(&TransactionStep{Type: StepRead, Offset: offset, Length: length})
+}
+
+// SparseRead reads the allocated extents of a length of the object starting at the given offset. Transactions with a
+// sparse read step are applied with OperateSparse.
+func (tx *ReadTransaction) SparseRead(offset, length uint64) *ReadTransaction {
+	return tx.add(&TransactionStep{Type: StepSparseRead, Offset: offset, Length: length})
+}
+
+func (tx *ReadTransaction) add(step *TransactionStep) *ReadTransaction {
+	tx.Steps = append(tx.Steps, step)
+	return tx
+}
+
+// Validate checks that the transaction can be applied. A read transaction must contain exactly one read or sparse read
+// step.
+func (tx *ReadTransaction) Validate() error {
+	if tx.flagsWithoutStep {
+		return invalidTransaction("Read transaction has flags set before any step.")
+	}
+	reads := 0
+	for i, step := range tx.Steps {
+		switch step.Type {
+		case StepAssertExists:
+		case StepCompareAttribute:
+			if step.Name == "" {
+				return invalidTransaction(fmt.Sprintf("Step %d: attribute name is required.", i))
+			}
+			if !step.Operator.valid() {
+				return invalidTransaction(fmt.Sprintf("Step %d: invalid compare operator %d.", i, step.Operator))
+			}
+		case StepRead, StepSparseRead:
+			if step.Length == 0 {
+				return invalidTransaction(fmt.Sprintf("Step %d: %s length must be greater than 0.", i, step.Type))
+			}
+			reads++
+		default:
+			return invalidTransaction(fmt.Sprintf("Step %d: %s is not a read step.", i, step.Type))
+		}
+	}
+	if reads != 1 {
+		return invalidTransaction(fmt.Sprintf("Read transaction should have exactly 1 read step, has %d.", reads))
+	}
+	return nil
+}
+
+// Operate validates the transaction and applies it to the object returning the data read. This can be called any number
+// of times on any number of objects.
+func (tx *ReadTransaction) Operate(object *Object, flags ...LibradosOperation) (io.Reader, error) {
+	if err := tx.Validate(); err != nil {
+		return nil, err
+	}
+	if tx.sparse() {
+		return nil, invalidTransaction("Read transaction has a sparse read step, use OperateSparse.")
+	}
+	ro, err := newReadOperation(object.ioContext)
+	if err != nil {
+		return nil, err
+	}
+	defer ro.Release()
+	tx.compile(ro)
+	return ro.Operate(object, flags...)
+}
+
+// sparse returns true if the read step of the transaction is a sparse read.
+func (tx *ReadTransaction) sparse() bool {
+	for _, step := range tx.Steps {
+		if step.Type == StepSparseRead {
+			return true
+		}
+	}
+	return false
+}
+
+// compile adds the steps of the transaction to the librados read operation. See WriteTransaction.compile.
+func (tx *ReadTransaction) compile(ro *ReadOperation) {
+	for _, step := range tx.Steps {
+		switch step.Type {
+		case StepAssertExists:
+			ro.AssertExists()
+		case StepCompareAttribute:
+			ro.CompareAttribute(step.Name, step.Operator, bytes.NewReader(step.Data))
+		case StepRead:
+			ro.Read(step.Offset, step.Length)
+		}
+		if step.Flags != 0 {
+			ro.SetFlags(step.Flags)
+		}
+	}
+}
+
+// valid returns true if the operator is a known comparison operator.
+func (operator CompareAttribute) valid() bool {
+	switch operator {
+	case Equal, NotEqual, GreaterThan, GreaterThanEqual, LessThan, LessThanEqual:
+		return true
+	}
+	return false
+}
+
+// readAll buffers the content of the reader so the transaction can be reused.
+func readAll(data io.Reader) []byte {
+	buf := new(bytes.Buffer)
+	if data != nil {
+		buf.ReadFrom(data)
+	}
+	return buf.Bytes()
+}
+
+// invalidTransaction creates the error returned for transactions that fail validation.
+func invalidTransaction(msg string) *RadosError {
+	return &RadosError{
+		Code:    -int(syscall.EINVAL),
+		Message: msg,
+	}
+}
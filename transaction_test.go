@@ -0,0 +1,137 @@
+package grados
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+)
+
+func TestWriteTransactionValidate(t *testing.T) {
+	if err := NewWriteTransaction().Validate(); err == nil {
+		t.Error("empty transaction should not be valid")
+	}
+	if err := NewWriteTransaction().SetAttribute("", bytes.NewBufferString("value")).Validate(); err == nil {
+		t.Error("attribute name should be required")
+	}
+	if err := NewWriteTransaction().Zero(0, 0).Validate(); err == nil {
+		t.Error("zero length should be required")
+	}
+	if err := NewWriteTransaction().SetFlags(OperationFailOk).Remove().Validate(); err == nil {
+		t.Error("flags should not be set before any step")
+	}
+	tx := NewWriteTransaction().
+		AssertExists().
+		CompareAttribute("version", Equal, bytes.NewBufferString("1")).
+		SetAttribute("version", bytes.NewBufferString("2")).
+		WriteFull(bytes.NewBufferString("data"))
+	handleError(t, tx.Validate())
+}
+
+func TestReadTransactionValidate(t *testing.T) {
+	if err := NewReadTransaction().AssertExists().Validate(); err == nil {
+		t.Error("read step should be required")
+	}
+	if err := NewReadTransaction().Read(0, 10).Read(10, 10).Validate(); err == nil {
+		t.Error("multiple read steps should not be valid")
+	}
+	if err := NewReadTransaction().SparseRead(0, 10).Read(10, 10).Validate(); err == nil {
+		t.Error("sparse read and read steps should not be valid together")
+	}
+	if _, err := NewReadTransaction().SparseRead(0, 10).Operate(&Object{name: "object"}); err == nil {
+		t.Error("sparse read step should require OperateSparse")
+	}
+	if _, err := NewReadTransaction().Read(0, 10).OperateSparse(&Object{name: "object"}); err == nil {
+		t.Error("OperateSparse should require a sparse read step")
+	}
+	handleError(t, NewReadTransaction().AssertExists().Read(0, 10).Validate())
+	handleError(t, NewReadTransaction().AssertExists().SparseRead(0, 10).Validate())
+}
+
+func TestTransactionSerialization(t *testing.T) {
+	tx := NewWriteTransaction().
+		SetAttribute("attrib", bytes.NewBufferString("value")).
+		SetFlags(OperationFailOk).
+		Write(bytes.NewBufferString("data"), 10)
+	data, err := json.Marshal(tx)
+	handleError(t, err)
+
+	replayed := new(WriteTransaction)
+	handleError(t, json.Unmarshal(data, replayed))
+	if len(replayed.Steps) != 2 || replayed.Steps[0].Flags != OperationFailOk || replayed.Steps[1].Flags != 0 {
+		t.Errorf("replayed transaction should match, replayed is %s", data)
+		return
+	}
+	if string(replayed.Steps[1].Data) != "data" || replayed.Steps[1].Offset != 10 {
+		t.Errorf("write step should match, step is %+v", replayed.Steps[1])
+	}
+}
+
+func TestWriteTransactionOperate(t *testing.T) {
+	cluster := connect(t)
+	if cluster == nil {
+		return
+	}
+	defer cluster.Shutdown()
+
+	pool, err := cluster.ManagePool("data")
+	handleError(t, err)
+	if pool == nil {
+		return
+	}
+	defer pool.Close()
+
+	tx := NewWriteTransaction().WriteFull(bytes.NewBufferString("replayed"))
+	for _, name := range []string{"tx_object1", "tx_object2"} {
+		object := pool.ManageObject(name)
+		handleError(t, tx.Operate(object, nil))
+		data, err := NewReadTransaction().Read(0, 8).Operate(object)
+		handleError(t, err)
+		if data != nil {
+			buf := new(bytes.Buffer)
+			buf.ReadFrom(data)
+			if buf.String() != "replayed" {
+				t.Errorf("data should be replayed, data is %s", buf.String())
+			}
+		}
+		object.Remove()
+	}
+}
+
+func TestWriteTransactionFlags(t *testing.T) {
+	cluster := connect(t)
+	if cluster == nil {
+		return
+	}
+	defer cluster.Shutdown()
+
+	pool, err := cluster.ManagePool("data")
+	handleError(t, err)
+	if pool == nil {
+		return
+	}
+	defer pool.Close()
+
+	object := pool.ManageObject("tx_flags")
+	handleError(t, object.WriteFull(bytes.NewBufferString("before")))
+	defer object.Remove()
+
+	tx := NewWriteTransaction().
+		RemoveAttribute("missing").
+		WriteFull(bytes.NewBufferString("after"))
+	if err := tx.Operate(object, nil); err == nil {
+		t.Error("removing a missing attribute should fail the transaction")
+	}
+	tx = NewWriteTransaction().
+		RemoveAttribute("missing").
+		SetFlags(OperationFailOk).
+		WriteFull(bytes.NewBufferString("after"))
+	handleError(t, tx.Operate(object, nil))
+
+	data, err := NewReadTransaction().Read(0, 5).SetFlags(OperationFailOk).Operate(object)
+	handleError(t, err)
+	if data != nil {
+		if value := string(readAll(data)); value != "after" {
+			t.Errorf("data should be after, data is %s", value)
+		}
+	}
+}
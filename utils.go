@@ -15,6 +15,9 @@ import (
 func readerToBuf(data io.Reader) (addr *C.char, length C.size_t) {
 	buf := new(bytes.Buffer)
 	buf.ReadFrom(data)
+	if buf.Len() == 0 {
+		return
+	}
 	addr = (*C.char)(unsafe.Pointer(&buf.Bytes()[0]))
 	length = C.size_t(buf.Len())
 	return
@@ -40,13 +43,12 @@ func freeString(str *C.char) {
 
 // bufToStringSlice converts a C buffer containing several strings separated by \0 to a string slice.
 func bufToStringSlice(bufAddr *C.char, ret C.int) []string {
-	reader := bufToReader(bufAddr, ret)
-	buf := new(bytes.Buffer)
-	buf.ReadFrom(reader)
-
 	result := make([]string, 0)
-	tmp := bytes.SplitAfter(buf.Bytes()[:ret-1], []byte{0})
-	for _, s := range tmp {
+	if ret <= 0 {
+		return result
+	}
+	b := C.GoBytes(unsafe.Pointer(bufAddr), ret)
+	for _, s := range bytes.Split(b, []byte{0}) {
 		if len(s) > 0 {
 			result = append(result, string(s))
 		}
@@ -3,20 +3,45 @@ package grados
 /*
 #cgo LDFLAGS: -lrados
 #include <rados/librados.h>
+
+// rados_write_op_operate2 (timespec mtime) is only available starting with librados 3. Older versions fall back to
+// rados_write_op_operate and lose the sub-second part of the modification time.
+static int write_op_operate(rados_write_op_t op, rados_ioctx_t io, const char *oid, struct timespec *mtime, int flags) {
+#if LIBRADOS_VER_MAJOR >= 3
+	return rados_write_op_operate2(op, io, oid, mtime, flags);
+#else
+	time_t t;
+	if (mtime == NULL) {
+		return rados_write_op_operate(op, io, oid, NULL, flags);
+	}
+	t = mtime->tv_sec;
+	return rados_write_op_operate(op, io, oid, &t, flags);
+#endif
+}
 */
 import "C"
 
 import (
+	"bytes"
+	"fmt"
 	"io"
+	"syscall"
 	"time"
 )
 
 type WriteOperation struct {
 	ioContext C.rados_ioctx_t
 	opContext C.rados_write_op_t
+	alignment AlignmentPolicy
+	err       error
 }
 
+// CreateWriteOperation creates a write operation on the pool. Appends follow the alignment policy of the pool.
 func (pool *Pool) CreateWriteOperation() (*WriteOperation, error) {
+	return newWriteOperation(pool.context, pool.alignment)
+}
+
+func newWriteOperation(ioContext C.rados_ioctx_t, alignment AlignmentPolicy) (*WriteOperation, error) {
 	opContext := C.rados_create_write_op()
 	if opContext == nil {
 		err := toRadosError(-1)
@@ -24,8 +49,9 @@ func (pool *Pool) CreateWriteOperation() (*WriteOperation, error) {
 		return nil, err
 	}
 	wo := &WriteOperation{
-		ioContext: pool.context,
+		ioContext: ioContext,
 		opContext: opContext,
+		alignment: alignment,
 	}
 	return wo, nil
 }
@@ -91,8 +117,20 @@ func (wo *WriteOperation) WriteFull(data io.Reader) *WriteOperation {
 	return wo
 }
 
+// Append appends the data to the object. The alignment policy of the pool is applied to the data. If the data is
+// rejected by the policy, the append is not added and Operate fails without sending the operation.
 func (wo *WriteOperation) Append(data io.Reader) *WriteOperation {
-	bufAddr, bufLen := readerToBuf(data)
+	buf := readAll(data)
+	aligned, alignment, ok := alignAppend(wo.ioContext, wo.alignment, buf)
+	if !ok {
+		if wo.err == nil {
+			err := toRadosError(-C.int(syscall.EINVAL))
+			err.Message = fmt.Sprintf("Unable to append %d bytes. The pool requires appends aligned to %d bytes.", len(buf), alignment)
+			wo.err = err
+		}
+		return wo
+	}
+	bufAddr, bufLen := readerToBuf(bytes.NewReader(aligned))
 	C.rados_write_op_append(wo.opContext, bufAddr, C.size_t(bufLen))
 	return wo
 }
@@ -112,13 +150,22 @@ func (wo *WriteOperation) Zero(offset, length uint64) *WriteOperation {
 	return wo
 }
 
+// Operate performs the write operation on the object. If modifiedTime is not nil, it is used as the modification time
+// of the object, with nanosecond precision if librados supports it. Otherwise the current time is used. This fails
+// without sending the operation if an append was rejected by the alignment policy.
 func (wo *WriteOperation) Operate(object *Object, modifiedTime *time.Time, flags ...LibradosOperation) error {
+	if wo.err != nil {
+		return wo.err
+	}
 	oid := C.CString(object.name)
 	defer freeString(oid)
 
-	var mtime C.time_t
+	var mtime *C.struct_timespec
 	if modifiedTime != nil {
-		mtime = C.time_t(modifiedTime.Unix())
+		mtime = &C.struct_timespec{
+			tv_sec:  C.time_t(modifiedTime.Unix()),
+			tv_nsec: C.long(modifiedTime.Nanosecond()),
+		}
 	}
 
 	var f C.int = 0
@@ -126,7 +173,7 @@ func (wo *WriteOperation) Operate(object *Object, modifiedTime *time.Time, flags
 		f |= C.int(flag)
 	}
 
-	ret := C.rados_write_op_operate(wo.opContext, wo.ioContext, oid, &mtime, f)
+	ret := C.write_op_operate(wo.opContext, wo.ioContext, oid, mtime, f)
 	if err := toRadosError(ret); err != nil {
 		err.Message = "Unable to perform write operation."
 		return err